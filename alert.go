@@ -0,0 +1,476 @@
+package discordant
+
+import (
+	ctx "context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultAlertSilenceDuration is the duration alert is silenced for with the
+// silence button.
+const DefaultAlertSilenceDuration = time.Hour
+
+// Alert button custom ids.
+const (
+	AlertActionAck     = "discordant_alert_ack"
+	AlertActionSilence = "discordant_alert_silence"
+)
+
+// Severity is an alert severity.
+type Severity int
+
+// Alert severities.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns severity name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return fmt.Sprintf("SEVERITY(%d)", int(s))
+	}
+}
+
+// color returns embed color of the severity.
+func (s Severity) color() int {
+	switch s {
+	case SeverityInfo:
+		return ColorBlue
+	case SeverityWarning:
+		return ColorYellow
+	default:
+		return ColorRed
+	}
+}
+
+// Alert is an incident notification. Alerts with the same key are grouped
+// into one message.
+type Alert struct {
+	Key      string
+	Severity Severity
+	Title    string
+	Text     string
+}
+
+// AlertStatus describes active alert.
+type AlertStatus struct {
+	Alert
+
+	Count         int
+	FirstSeen     time.Time
+	LastSeen      time.Time
+	ChannelID     string
+	MessageID     string
+	AckedBy       string
+	AckedAt       time.Time
+	SilencedBy    string
+	SilencedUntil time.Time
+}
+
+// silenced returns true if the alert is silenced at the moment.
+func (s *AlertStatus) silenced(now time.Time) bool {
+	return now.Before(s.SilencedUntil)
+}
+
+// AlertOption can be used to customize Alerter.
+type AlertOption func(a *Alerter)
+
+// AlertRoles allows members with any of the roles to acknowledge and silence
+// alerts. By default only members with manage messages permission in the
+// alert channel can do it.
+func AlertRoles(roles ...string) AlertOption {
+	return func(a *Alerter) {
+		a.roles = roles
+	}
+}
+
+// AlertSilenceDuration sets the duration alert is silenced for with the
+// silence button.
+func AlertSilenceDuration(duration time.Duration) AlertOption {
+	return func(a *Alerter) {
+		a.silence = duration
+	}
+}
+
+// Alerter posts keyed alerts to Discord channel as embeds. Repeats of the
+// same key update the existing message instead of posting a new one. Active
+// alert message has acknowledge and silence buttons that record who acted.
+// Resolved alert message is edited to green.
+type Alerter struct {
+	discordant *Discordant
+	channel    string
+	silence    time.Duration
+	roles      []string
+	remove     func()
+
+	// sendMu serializes posts and edits of alert messages. Button handler
+	// doesn't take it, so slow Discord calls don't delay button responses.
+	sendMu sync.Mutex
+
+	mu     sync.Mutex
+	alerts map[string]*AlertStatus
+}
+
+// NewAlerter creates Alerter that posts alerts to the channel resolved by
+// name from Config.Channels and handles its buttons.
+func NewAlerter(d *Discordant, channelName string, options ...AlertOption) (*Alerter, error) {
+	if channelName == "" {
+		return nil, fmt.Errorf("discordant alert: %w", ErrEmptyChannelID)
+	}
+
+	if _, err := d.channelID(channelName); err != nil {
+		return nil, fmt.Errorf("discordant alert: %w", err)
+	}
+
+	a := Alerter{
+		discordant: d,
+		channel:    channelName,
+		silence:    DefaultAlertSilenceDuration,
+		alerts:     make(map[string]*AlertStatus),
+	}
+
+	for _, option := range options {
+		option(&a)
+	}
+
+	a.remove = d.AddHandler(a.interactionHandler)
+
+	return &a, nil
+}
+
+// Close stops handling of alert buttons.
+func (a *Alerter) Close() {
+	a.remove()
+}
+
+// Fire posts the alert or updates the message of active alert with the same
+// key. Silenced alert is counted, but its message is not updated until the
+// silence is over.
+func (a *Alerter) Fire(alert Alert) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+
+	now := time.Now()
+
+	a.mu.Lock()
+
+	status, ok := a.alerts[alert.Key]
+	if !ok {
+		a.mu.Unlock()
+
+		return a.post(alert, now)
+	}
+
+	status.Alert = alert
+	status.Count++
+	status.LastSeen = now
+
+	if status.silenced(now) {
+		a.mu.Unlock()
+
+		return nil
+	}
+
+	channelID, messageID := status.ChannelID, status.MessageID
+	embed, components := status.embed(), status.components()
+
+	a.mu.Unlock()
+
+	if err := a.edit(channelID, messageID, embed, components); err != nil {
+		return fmt.Errorf("discordant alert: %w", err)
+	}
+
+	return nil
+}
+
+// post posts message of new alert. It must be called under sendMu.
+func (a *Alerter) post(alert Alert, now time.Time) error {
+	channelID, err := a.discordant.channelID(a.channel)
+	if err != nil {
+		return fmt.Errorf("discordant alert: %w", err)
+	}
+
+	status := &AlertStatus{Alert: alert, Count: 1, FirstSeen: now, LastSeen: now, ChannelID: channelID}
+
+	msg, err := a.discordant.sendComplex(ctx.Background(), channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{status.embed()},
+		Components: status.components(),
+	})
+	if err != nil {
+		return fmt.Errorf("discordant alert: %w", err)
+	}
+
+	status.MessageID = msg.ID
+
+	a.mu.Lock()
+	a.alerts[alert.Key] = status
+	a.mu.Unlock()
+
+	return nil
+}
+
+// Resolve edits the message of active alert to green and removes its buttons.
+func (a *Alerter) Resolve(key string) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+
+	a.mu.Lock()
+
+	status, ok := a.alerts[key]
+	if !ok {
+		a.mu.Unlock()
+
+		return fmt.Errorf("discordant alert: %w: %s", ErrUnknownAlert, key)
+	}
+
+	delete(a.alerts, key)
+
+	embed := status.embed()
+	embed.Title = "[RESOLVED] " + status.title()
+	embed.Color = ColorGreen
+	embed.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	a.mu.Unlock()
+
+	if err := a.edit(status.ChannelID, status.MessageID, embed, []discordgo.MessageComponent{}); err != nil {
+		return fmt.Errorf("discordant alert: %w", err)
+	}
+
+	return nil
+}
+
+// Active returns active alerts sorted by key.
+func (a *Alerter) Active() []AlertStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	statuses := make([]AlertStatus, 0, len(a.alerts))
+
+	for _, status := range a.alerts {
+		statuses = append(statuses, *status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
+
+	return statuses
+}
+
+func (a *Alerter) edit(
+	channelID, messageID string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent,
+) error {
+	embeds := []*discordgo.MessageEmbed{embed}
+
+	_, err := a.discordant.editComplex(ctx.Background(), &discordgo.MessageEdit{
+		ID:         messageID,
+		Channel:    channelID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+
+	return err
+}
+
+// interactionHandler handles acknowledge and silence buttons. Buttons of
+// messages posted by other Alerters or before restart are left to their
+// owners, so that the interaction gets only one response.
+func (a *Alerter) interactionHandler(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent || i.Message == nil {
+		return
+	}
+
+	action := i.MessageComponentData().CustomID
+	if action != AlertActionAck && action != AlertActionSilence {
+		return
+	}
+
+	a.mu.Lock()
+	owned := a.find(i.Message.ID) != nil
+	a.mu.Unlock()
+
+	if !owned {
+		return
+	}
+
+	var resp *discordgo.InteractionResponse
+
+	if a.allowed(i.Member) {
+		resp = a.act(action, i.Message.ID, interactionUser(i.Interaction))
+	} else {
+		resp = ephemeralResponse("You are not allowed to manage alerts.")
+	}
+
+	if err := a.discordant.respondInteraction(ctx.Background(), i.Interaction, resp); err != nil {
+		a.discordant.logger.Errorf("discordant alert: respond to %s: %s", action, err)
+	}
+}
+
+// act records the action and returns interaction response.
+func (a *Alerter) act(action, messageID string, user *discordgo.User) *discordgo.InteractionResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// The alert can be resolved after the button is pressed.
+	status := a.find(messageID)
+	if status == nil {
+		return ephemeralResponse("Alert is already resolved.")
+	}
+
+	name := "unknown"
+	if user != nil {
+		name = user.Username
+	}
+
+	now := time.Now()
+
+	switch action {
+	case AlertActionAck:
+		status.AckedBy = name
+		status.AckedAt = now
+
+		a.discordant.logger.Infof("discordant alert: %s acknowledged by %s", status.Key, name)
+	case AlertActionSilence:
+		status.SilencedBy = name
+		status.SilencedUntil = now.Add(a.silence)
+
+		a.discordant.logger.Infof("discordant alert: %s silenced by %s for %s", status.Key, name, a.silence)
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{status.embed()},
+			Components: status.components(),
+		},
+	}
+}
+
+// find returns active alert posted with the message. It must be called under
+// the lock.
+func (a *Alerter) find(messageID string) *AlertStatus {
+	for _, status := range a.alerts {
+		if status.MessageID == messageID {
+			return status
+		}
+	}
+
+	return nil
+}
+
+// allowed returns true if the member can acknowledge and silence alerts.
+func (a *Alerter) allowed(member *discordgo.Member) bool {
+	if member == nil {
+		return false
+	}
+
+	if len(a.roles) == 0 {
+		return member.Permissions&(discordgo.PermissionManageMessages|discordgo.PermissionAdministrator) != 0
+	}
+
+	for _, role := range a.roles {
+		for _, memberRole := range member.Roles {
+			if role == memberRole {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ephemeralResponse returns interaction response visible only to the user.
+func ephemeralResponse(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+func (s *AlertStatus) title() string {
+	if s.Title != "" {
+		return s.Title
+	}
+
+	return s.Key
+}
+
+func (s *AlertStatus) embed() *discordgo.MessageEmbed {
+	embed := discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("[%s] %s", s.Severity, s.title()),
+		Description: s.Text,
+		Color:       s.Severity.color(),
+		Timestamp:   s.LastSeen.UTC().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Key", Value: s.Key, Inline: true},
+			{Name: "Count", Value: fmt.Sprint(s.Count), Inline: true},
+			{Name: "First seen", Value: discordTimestamp(s.FirstSeen), Inline: true},
+		},
+	}
+
+	var footer []string
+
+	if s.AckedBy != "" {
+		footer = append(footer, fmt.Sprintf("acknowledged by %s at %s", s.AckedBy, s.AckedAt.UTC().Format(time.RFC3339)))
+	}
+
+	if s.SilencedBy != "" {
+		footer = append(footer, fmt.Sprintf("silenced by %s until %s", s.SilencedBy,
+			s.SilencedUntil.UTC().Format(time.RFC3339)))
+	}
+
+	if len(footer) != 0 {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: strings.Join(footer, "; ")}
+	}
+
+	return &embed
+}
+
+func (s *AlertStatus) components() []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Acknowledge",
+				Style:    discordgo.PrimaryButton,
+				CustomID: AlertActionAck,
+				Disabled: s.AckedBy != "",
+			},
+			discordgo.Button{
+				Label:    "Silence",
+				Style:    discordgo.SecondaryButton,
+				CustomID: AlertActionSilence,
+			},
+		}},
+	}
+}
+
+// interactionUser returns user who triggered the interaction in guild or DM.
+func interactionUser(i *discordgo.Interaction) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+
+	return i.User
+}
+
+// discordTimestamp formats time as Discord relative timestamp.
+func discordTimestamp(t time.Time) string {
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
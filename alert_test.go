@@ -0,0 +1,158 @@
+package discordant_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func newAlerter(t *testing.T, h *discordanttest.Harness, options ...discordant.AlertOption) *discordant.Alerter {
+	t.Helper()
+
+	alerter, err := discordant.NewAlerter(h.Bot, discordant.ChannelAdmin, options...)
+	if err != nil {
+		t.Fatalf("NewAlerter: %s", err)
+	}
+
+	t.Cleanup(alerter.Close)
+
+	return alerter
+}
+
+func TestAlerterFireResolve(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	alerter := newAlerter(t, h)
+
+	alert := discordant.Alert{Key: "disk", Severity: discordant.SeverityCritical, Title: "Disk is full"}
+
+	for range 3 {
+		if err := alerter.Fire(alert); err != nil {
+			t.Fatalf("Fire: %s", err)
+		}
+	}
+
+	h.AssertMessagesCount(1)
+
+	active := alerter.Active()
+	if len(active) != 1 || active[0].Count != 3 {
+		t.Fatalf("active alerts: %+v", active)
+	}
+
+	if err := alerter.Resolve("disk"); err != nil {
+		t.Fatalf("Resolve: %s", err)
+	}
+
+	msg := h.LastMessage()
+	if len(msg.Embeds) != 1 || !strings.HasPrefix(msg.Embeds[0].Title, "[RESOLVED]") {
+		t.Errorf("resolved message embeds: %+v", msg.Embeds)
+	}
+
+	if len(msg.Components) != 0 {
+		t.Errorf("resolved message has buttons")
+	}
+
+	if len(alerter.Active()) != 0 {
+		t.Errorf("alert is active after resolve")
+	}
+}
+
+func TestAlerterButtonsPermission(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	alerter := newAlerter(t, h)
+
+	if err := alerter.Fire(discordant.Alert{Key: "cpu", Title: "CPU load"}); err != nil {
+		t.Fatalf("Fire: %s", err)
+	}
+
+	msg := h.LastMessage()
+
+	h.Click(msg, discordant.AlertActionSilence)
+
+	resp := h.Interactions()[0].Response
+	if resp.Data == nil || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Fatalf("denied click response: %+v", resp)
+	}
+
+	if active := alerter.Active(); active[0].SilencedBy != "" {
+		t.Fatalf("alert is silenced by %s without permission", active[0].SilencedBy)
+	}
+
+	h.ClickAs(msg, discordant.AlertActionAck, &discordgo.Member{
+		GuildID:     discordanttest.GuildID,
+		User:        &discordgo.User{ID: discordanttest.UserID, Username: "admin"},
+		Permissions: discordgo.PermissionManageMessages,
+	})
+
+	if active := alerter.Active(); active[0].AckedBy != "admin" {
+		t.Errorf("alert is acked by %q, want admin", active[0].AckedBy)
+	}
+}
+
+func TestAlerterRoles(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	alerter := newAlerter(t, h, discordant.AlertRoles("oncall"))
+
+	if err := alerter.Fire(discordant.Alert{Key: "cpu", Title: "CPU load"}); err != nil {
+		t.Fatalf("Fire: %s", err)
+	}
+
+	msg := h.LastMessage()
+	user := &discordgo.User{ID: discordanttest.UserID, Username: "user"}
+
+	h.ClickAs(msg, discordant.AlertActionAck, &discordgo.Member{
+		GuildID:     discordanttest.GuildID,
+		User:        user,
+		Permissions: discordgo.PermissionAdministrator,
+	})
+
+	if active := alerter.Active(); active[0].AckedBy != "" {
+		t.Fatalf("alert is acked without role")
+	}
+
+	h.ClickAs(msg, discordant.AlertActionAck, &discordgo.Member{
+		GuildID: discordanttest.GuildID,
+		User:    user,
+		Roles:   []string{"oncall"},
+	})
+
+	if active := alerter.Active(); active[0].AckedBy != "user" {
+		t.Errorf("alert is acked by %q, want user", active[0].AckedBy)
+	}
+}
+
+func TestAlerterIgnoresForeignButtons(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	owner := newAlerter(t, h)
+	newAlerter(t, h)
+
+	if err := owner.Fire(discordant.Alert{Key: "cpu", Title: "CPU load"}); err != nil {
+		t.Fatalf("Fire: %s", err)
+	}
+
+	admin := &discordgo.Member{
+		GuildID:     discordanttest.GuildID,
+		User:        &discordgo.User{ID: discordanttest.UserID, Username: "admin"},
+		Permissions: discordgo.PermissionManageMessages,
+	}
+
+	h.ClickAs(h.LastMessage(), discordant.AlertActionAck, admin)
+
+	if n := len(h.Interactions()); n != 1 {
+		t.Fatalf("click is responded %d times, want 1", n)
+	}
+
+	if active := owner.Active(); active[0].AckedBy != "admin" {
+		t.Errorf("alert is acked by %q, want admin", active[0].AckedBy)
+	}
+
+	// Message posted before restart is not known to any Alerter.
+	h.ClickAs(discordanttest.Message{ID: "800000000000000099", ChannelID: discordanttest.AdminChannelID},
+		discordant.AlertActionAck, admin)
+
+	if n := len(h.Interactions()); n != 1 {
+		t.Errorf("unknown alert message is responded")
+	}
+}
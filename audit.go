@@ -0,0 +1,203 @@
+package discordant
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AuditRedacted replaces arguments of sensitive commands in audit records.
+const AuditRedacted = "[REDACTED]"
+
+// AuditRecord describes a single command invocation.
+type AuditRecord struct {
+	Time      time.Time     `json:"time"`
+	RequestID string        `json:"request_id"`
+	Command   string        `json:"command"`
+	Arg       string        `json:"arg"`
+	UserID    string        `json:"user_id"`
+	Username  string        `json:"username"`
+	GuildID   string        `json:"guild_id"`
+	ChannelID string        `json:"channel_id"`
+	Allowed   bool          `json:"allowed"`
+	Outcome   string        `json:"outcome"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// AuditSink receives audit records.
+type AuditSink interface {
+	Audit(record AuditRecord) error
+}
+
+// AuditSinkFunc is an adapter to use ordinary function as AuditSink.
+type AuditSinkFunc func(record AuditRecord) error
+
+// Audit calls f(record).
+func (f AuditSinkFunc) Audit(record AuditRecord) error {
+	return f(record)
+}
+
+// MiddlewareAudit enables audit of the command invocations.
+func MiddlewareAudit() CommandOption {
+	return func(c *Command) {
+		c.audit = true
+	}
+}
+
+// MiddlewareSensitive marks command arguments as sensitive. Arguments and
+// handler errors, which may quote them, are replaced with AuditRedacted in
+// audit records.
+func MiddlewareSensitive() CommandOption {
+	return func(c *Command) {
+		c.sensitive = true
+	}
+}
+
+// AuditFileSink writes audit records to file in JSON lines format.
+type AuditFileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewAuditFileSink opens file for appending audit records. The caller should
+// call Close when finished.
+func NewAuditFileSink(path string) (*AuditFileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("discordant audit: open file: %w", err)
+	}
+
+	return &AuditFileSink{file: file}, nil
+}
+
+// Audit writes record as a single JSON line.
+func (s *AuditFileSink) Audit(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("discordant audit: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("discordant audit: write file: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes file.
+func (s *AuditFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// auditChannelSink posts audit records to Discord channel.
+type auditChannelSink struct {
+	discordant *Discordant
+	channel    string
+}
+
+// Audit posts record to the channel.
+func (s *auditChannelSink) Audit(record AuditRecord) error {
+	channelID, err := s.discordant.channelID(s.channel)
+	if err != nil {
+		return fmt.Errorf("discordant audit: %w", err)
+	}
+
+	// Username, argument and error are user input, so they must not close
+	// the code block.
+	msg := fmt.Sprintf("```%s %s by %s (%s) in <#%s>: %s",
+		record.Time.UTC().Format(time.RFC3339), record.Command, escapeCodeBlock(record.Username), record.UserID,
+		record.ChannelID, record.Outcome)
+
+	if record.Arg != "" {
+		msg += "\narg: " + escapeCodeBlock(record.Arg)
+	}
+
+	if record.Error != "" {
+		msg += "\nerror: " + escapeCodeBlock(record.Error)
+	}
+
+	msg += fmt.Sprintf("\nduration: %s request: %s```", record.Duration, record.RequestID)
+
+	if len([]rune(msg)) > DiscordMaxMessageLenValidate {
+		msg = string([]rune(msg)[:DiscordMaxMessageLenValidate-3]) + "```"
+	}
+
+	err = s.discordant.sendMentions(ctx.Background(), channelID, msg, &discordgo.MessageAllowedMentions{})
+	if err != nil {
+		return fmt.Errorf("discordant audit: %w", err)
+	}
+
+	return nil
+}
+
+// escapeCodeBlock separates backticks with zero width spaces, so that text
+// can't close the code block.
+func escapeCodeBlock(text string) string {
+	return strings.ReplaceAll(text, "`", "`\u200b")
+}
+
+// audit sends invocation record to audit sinks if audit is enabled for the command.
+// Sinks are called synchronously one by one.
+func (d *Discordant) audit(ctx Context, outcome string, duration time.Duration, err error) {
+	command := ctx.Command()
+	if !command.audit || (len(d.auditSinks) == 0 && d.auditChannel == "") {
+		return
+	}
+
+	request := ctx.Request()
+
+	record := AuditRecord{
+		Time:      time.Now(),
+		RequestID: ctx.RequestID(),
+		Command:   command.Name,
+		Arg:       command.Arg,
+		GuildID:   request.GuildID,
+		ChannelID: request.ChannelID,
+		Allowed:   outcome != OutcomeDenied,
+		Outcome:   outcome,
+		Duration:  duration,
+	}
+
+	if request.Author != nil {
+		record.UserID = request.Author.ID
+		record.Username = request.Author.Username
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if command.sensitive {
+		if record.Arg != "" {
+			record.Arg = AuditRedacted
+		}
+
+		if record.Error != "" {
+			record.Error = AuditRedacted
+		}
+	}
+
+	sinks := d.auditSinks
+	if d.auditChannel != "" {
+		sinks = append(sinks[:len(sinks):len(sinks)], &auditChannelSink{discordant: d, channel: d.auditChannel})
+	}
+
+	for _, sink := range sinks {
+		if err := sink.Audit(record); err != nil {
+			ctx.Logger().Errorf("discordant: audit: %s", err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package discordant_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestAuditChannelEscapesArg(t *testing.T) {
+	h := discordanttest.New(t, nil, discordant.SetAuditChannel(discordant.ChannelAdmin))
+
+	h.Bot.ALL("echo", func(ctx discordant.Context) error {
+		return ctx.Success()
+	}, discordant.MiddlewareAudit())
+
+	h.Exec(discordanttest.GeneralChannelID, "!echo ``` @everyone <@&1> ```")
+
+	var audit []discordanttest.Message
+
+	for _, msg := range h.Messages() {
+		if msg.ChannelID == discordanttest.AdminChannelID {
+			audit = append(audit, msg)
+		}
+	}
+
+	if len(audit) != 1 {
+		t.Fatalf("expected 1 audit message, got %d", len(audit))
+	}
+
+	msg := audit[0]
+
+	if n := strings.Count(msg.Content, "```"); n != 2 {
+		t.Errorf("audit message has %d code fences: %q", n, msg.Content)
+	}
+
+	if !strings.Contains(msg.Content, "@everyone") {
+		t.Errorf("audit message has no argument: %q", msg.Content)
+	}
+
+	if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 ||
+		len(msg.AllowedMentions.Users) != 0 || len(msg.AllowedMentions.Roles) != 0 {
+		t.Errorf("audit message allows mentions: %+v", msg.AllowedMentions)
+	}
+}
+
+func TestAuditSinkFunc(t *testing.T) {
+	var records []discordant.AuditRecord
+
+	sink := discordant.AuditSinkFunc(func(record discordant.AuditRecord) error {
+		records = append(records, record)
+
+		return nil
+	})
+
+	h := discordanttest.New(t, nil, discordant.SetAuditSinks(sink))
+
+	h.Bot.ADMIN("ban", func(ctx discordant.Context) error { return ctx.Success() }, discordant.MiddlewareAudit())
+	h.Bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Send("pong") })
+
+	h.Exec(discordanttest.GeneralChannelID, "!ban john")
+	h.Exec(discordanttest.AdminChannelID, "!ban john")
+	h.Exec(discordanttest.AdminChannelID, "!ping")
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %+v", len(records), records)
+	}
+
+	denied, allowed := records[0], records[1]
+
+	if denied.Allowed || denied.Outcome != discordant.OutcomeDenied ||
+		denied.ChannelID != discordanttest.GeneralChannelID {
+		t.Errorf("denied record: %+v", denied)
+	}
+
+	if !allowed.Allowed || allowed.Outcome != discordant.OutcomeSuccess || allowed.Command != "ban" ||
+		allowed.Arg != "john" || allowed.UserID != discordanttest.UserID || allowed.RequestID == "" {
+		t.Errorf("allowed record: %+v", allowed)
+	}
+}
+
+func TestAuditFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := discordant.NewAuditFileSink(path)
+	if err != nil {
+		t.Fatalf("NewAuditFileSink: %s", err)
+	}
+
+	h := discordanttest.New(t, nil, discordant.SetAuditSinks(sink))
+
+	h.Bot.ALL("login", func(ctx discordant.Context) error {
+		return errors.New("invalid password " + ctx.QueryString())
+	}, discordant.MiddlewareAudit(), discordant.MiddlewareSensitive())
+	h.Bot.ALL("echo", func(ctx discordant.Context) error {
+		return errors.New("echo " + ctx.QueryString())
+	}, discordant.MiddlewareAudit())
+
+	h.Exec(discordanttest.GeneralChannelID, "!login hunter2")
+	h.Exec(discordanttest.GeneralChannelID, "!echo hello")
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records []discordant.AuditRecord
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record discordant.AuditRecord
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q: %s", scanner.Text(), err)
+		}
+
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	if records[0].Arg != discordant.AuditRedacted || records[0].Error != discordant.AuditRedacted {
+		t.Errorf("sensitive record is not redacted: %+v", records[0])
+	}
+
+	if records[1].Arg != "hello" || records[1].Error != "echo hello" || records[1].Outcome != discordant.OutcomeError {
+		t.Errorf("record: %+v", records[1])
+	}
+
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "hunter2") {
+		t.Errorf("audit file has sensitive argument: %s", data)
+	}
+}
@@ -7,7 +7,18 @@ type Command struct {
 	Description string   `json:"description"`
 	Help        string   `json:"help"`
 	Access      []string `json:"access"`
+	Disabled    bool     `json:"disabled"`
 	action      HandlerFunc
+	success     *Response
+	fail        *Response
+	serial      SerialScope
+	audit       bool
+	sensitive   bool
+
+	// declaredAccess is access set by options. Access is calculated from it
+	// and access order and is recalculated on Reload.
+	declaredAccess []string
+	accessAll      bool
 }
 
 // CommandOption describes command option func.
@@ -20,6 +31,13 @@ func MiddlewareAccess(access ...string) CommandOption {
 	}
 }
 
+// middlewareAccessAll allows access from every channel of access order.
+func middlewareAccessAll() CommandOption {
+	return func(c *Command) {
+		c.accessAll = true
+	}
+}
+
 // MiddlewareDescription adds description to command.
 func MiddlewareDescription(description string) CommandOption {
 	return func(c *Command) {
@@ -1,12 +1,202 @@
 package discordant
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables that override config values.
+const (
+	EnvVarPrefix   = "DISCORDANT_"
+	EnvToken       = EnvVarPrefix + "TOKEN"
+	EnvTokenFile   = EnvVarPrefix + "TOKEN_FILE"
+	EnvPrefix      = EnvVarPrefix + "PREFIX"
+	EnvSafemode    = EnvVarPrefix + "SAFEMODE"
+	EnvAccessOrder = EnvVarPrefix + "ACCESS_ORDER"
+	EnvShardCount  = EnvVarPrefix + "SHARD_COUNT"
+	EnvAutoShard   = EnvVarPrefix + "AUTO_SHARD"
+	EnvShards      = EnvVarPrefix + "SHARDS"
+
+	// EnvChannelPrefix is a prefix of channel id variables such as
+	// DISCORDANT_CHANNEL_ADMIN. Channel name is lowercased.
+	EnvChannelPrefix = EnvVarPrefix + "CHANNEL_"
+)
+
+// Snowflake length limits.
+const (
+	snowflakeMinLen = 17
+	snowflakeMaxLen = 20
+)
+
 // Config contains credentials for Discord server.
 type Config struct {
 	Token       string            `json:"token" yaml:"token"`
+	TokenFile   string            `json:"token_file" yaml:"token_file"`
 	Prefix      string            `json:"prefix" yaml:"prefix"`
 	Safemode    bool              `json:"safemode" yaml:"safemode"`
 	Channels    map[string]string `json:"channels" yaml:"channels"`
 	AccessOrder []string          `json:"access_order" yaml:"access_order"`
+
+	// ShardCount is the total number of gateway shards. Zero means single
+	// connection without sharding.
+	ShardCount int `json:"shard_count" yaml:"shard_count"`
+
+	// AutoShard takes shard count recommended by Discord instead of ShardCount.
+	AutoShard bool `json:"auto_shard" yaml:"auto_shard"`
+
+	// Shards is the subset of shard ids opened by the process. It allows to
+	// spread shards over several processes. Empty means all shards.
+	Shards []int `json:"shards" yaml:"shards"`
+}
+
+// LoadConfig reads config from YAML or JSON file by its extension, applies
+// environment variables overrides, reads token from TokenFile if token is
+// empty and validates the result. If path is empty config is built from
+// environment variables only.
+func LoadConfig(path string) (*Config, error) {
+	cfg := Config{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("discordant: read config: %w", err)
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &cfg)
+		case ".json":
+			err = json.Unmarshal(data, &cfg)
+		default:
+			err = fmt.Errorf("%w: %s", ErrUnknownConfigFormat, filepath.Ext(path))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("discordant: parse config: %w", err)
+		}
+	}
+
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ReadTokenFile(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// ApplyEnv overrides config values with DISCORDANT_* environment variables.
+func (cfg *Config) ApplyEnv() error {
+	if value, ok := os.LookupEnv(EnvToken); ok {
+		cfg.Token = value
+	}
+
+	if value, ok := os.LookupEnv(EnvTokenFile); ok {
+		cfg.TokenFile = value
+	}
+
+	if value, ok := os.LookupEnv(EnvPrefix); ok {
+		cfg.Prefix = value
+	}
+
+	if value, ok := os.LookupEnv(EnvSafemode); ok {
+		safemode, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("discordant: parse %s: %w", EnvSafemode, err)
+		}
+
+		cfg.Safemode = safemode
+	}
+
+	if value, ok := os.LookupEnv(EnvAccessOrder); ok {
+		cfg.AccessOrder = nil
+
+		for _, channel := range strings.Split(value, ",") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				cfg.AccessOrder = append(cfg.AccessOrder, channel)
+			}
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvShardCount); ok {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("discordant: parse %s: %w", EnvShardCount, err)
+		}
+
+		cfg.ShardCount = count
+	}
+
+	if value, ok := os.LookupEnv(EnvAutoShard); ok {
+		auto, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("discordant: parse %s: %w", EnvAutoShard, err)
+		}
+
+		cfg.AutoShard = auto
+	}
+
+	if value, ok := os.LookupEnv(EnvShards); ok {
+		cfg.Shards = nil
+
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+
+			shard, err := strconv.Atoi(id)
+			if err != nil {
+				return fmt.Errorf("discordant: parse %s: %w", EnvShards, err)
+			}
+
+			cfg.Shards = append(cfg.Shards, shard)
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, EnvChannelPrefix) || len(key) == len(EnvChannelPrefix) {
+			continue
+		}
+
+		if cfg.Channels == nil {
+			cfg.Channels = make(map[string]string)
+		}
+
+		cfg.Channels[strings.ToLower(strings.TrimPrefix(key, EnvChannelPrefix))] = value
+	}
+
+	return nil
+}
+
+// ReadTokenFile reads token from TokenFile if Token is empty. It allows to
+// pass the token as container secret.
+func (cfg *Config) ReadTokenFile() error {
+	if cfg.Token != "" || cfg.TokenFile == "" {
+		return nil
+	}
+
+	token, err := os.ReadFile(cfg.TokenFile)
+	if err != nil {
+		return fmt.Errorf("discordant: read token file: %w", err)
+	}
+
+	cfg.Token = strings.TrimSpace(string(token))
+
+	return nil
 }
 
 // Validate checks required fields and validates for allowed values.
@@ -15,5 +205,46 @@ func (cfg *Config) Validate() error {
 		return ErrEmptyPrefix
 	}
 
+	if strings.IndexFunc(cfg.Prefix, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidPrefix, cfg.Prefix)
+	}
+
+	for name, id := range cfg.Channels {
+		if id == "" {
+			return fmt.Errorf("%w: %s", ErrEmptyChannelID, name)
+		}
+
+		if !IsSnowflake(id) {
+			return fmt.Errorf("%w: %s: %q", ErrInvalidChannelID, name, id)
+		}
+	}
+
+	for _, name := range cfg.AccessOrder {
+		if _, ok := cfg.Channels[name]; !ok {
+			return fmt.Errorf("%w: access order: %s", ErrUnknownChannel, name)
+		}
+	}
+
+	if cfg.ShardCount < 0 {
+		return fmt.Errorf("%w: shard count %d", ErrInvalidShard, cfg.ShardCount)
+	}
+
+	for _, id := range cfg.Shards {
+		if id < 0 || (!cfg.AutoShard && id >= max(cfg.ShardCount, 1)) {
+			return fmt.Errorf("%w: %d of %d", ErrInvalidShard, id, cfg.ShardCount)
+		}
+	}
+
 	return nil
 }
+
+// IsSnowflake returns true if id looks like Discord snowflake id.
+func IsSnowflake(id string) bool {
+	if len(id) < snowflakeMinLen || len(id) > snowflakeMaxLen {
+		return false
+	}
+
+	_, err := strconv.ParseUint(id, 10, 64)
+
+	return err == nil
+}
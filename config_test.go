@@ -0,0 +1,167 @@
+package discordant_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/outdead/discordant"
+)
+
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected discordant.Config
+	}{
+		{
+			name: "plain",
+			data: `
+token: secret
+prefix: "!"
+safemode: yes
+channels:
+  general: 700000000000000002
+  admin: "700000000000000003"
+access_order: [admin, general]
+shard_count: 2
+shards:
+  - 1
+`,
+			expected: discordant.Config{
+				Token:    "secret",
+				Prefix:   "!",
+				Safemode: true,
+				Channels: map[string]string{
+					"general": "700000000000000002",
+					"admin":   "700000000000000003",
+				},
+				AccessOrder: []string{"admin", "general"},
+				ShardCount:  2,
+				Shards:      []int{1},
+			},
+		},
+		{
+			name: "apostrophe before comment",
+			data: `
+token: it's # not a part of the token
+prefix: don't # comment with 'quote
+`,
+			expected: discordant.Config{Token: "it's", Prefix: "don't"},
+		},
+		{
+			name: "block scalar",
+			data: `
+token: >-
+  first
+  second
+prefix: |-
+  !
+`,
+			expected: discordant.Config{Token: "first second", Prefix: "!"},
+		},
+		{
+			name: "anchors",
+			data: `
+x-channels: &channels
+  general: "700000000000000002"
+prefix: "!"
+channels: *channels
+safemode: off
+`,
+			expected: discordant.Config{
+				Prefix:   "!",
+				Channels: map[string]string{"general": "700000000000000002"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := discordant.LoadConfig(writeConfig(t, "config.yaml", tt.data))
+			if err != nil {
+				t.Fatalf("LoadConfig: %s", err)
+			}
+
+			if !reflect.DeepEqual(*cfg, tt.expected) {
+				t.Errorf("got %+v, want %+v", *cfg, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		err  error
+	}{
+		{name: "unknown format", file: "config.toml", data: `prefix = "!"`, err: discordant.ErrUnknownConfigFormat},
+		{name: "empty prefix", file: "config.yml", data: "token: secret\n", err: discordant.ErrEmptyPrefix},
+		{name: "syntax", file: "config.yaml", data: "prefix: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := discordant.LoadConfig(writeConfig(t, tt.file, tt.data))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got %s, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  discordant.Config
+		err  error
+	}{
+		{
+			name: "valid",
+			cfg: discordant.Config{
+				Prefix:      "!",
+				Channels:    map[string]string{"admin": "700000000000000003"},
+				AccessOrder: []string{"admin"},
+			},
+		},
+		{name: "prefix with space", cfg: discordant.Config{Prefix: "! "}, err: discordant.ErrInvalidPrefix},
+		{
+			name: "channel name instead of id",
+			cfg:  discordant.Config{Prefix: "!", Channels: map[string]string{"admin": "admin-channel"}},
+			err:  discordant.ErrInvalidChannelID,
+		},
+		{
+			name: "unknown access order channel",
+			cfg:  discordant.Config{Prefix: "!", AccessOrder: []string{"admin"}},
+			err:  discordant.ErrUnknownChannel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
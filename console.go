@@ -0,0 +1,315 @@
+package discordant
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant/internal/events"
+)
+
+// Console defaults.
+const (
+	ConsoleBotID     = "100000000000000001"
+	ConsoleUserID    = "100000000000000002"
+	ConsoleChannelID = "100000000000000003"
+	ConsoleGuildID   = "100000000000000004"
+)
+
+// Console directives. Lines that start with them are not sent to the bot.
+const (
+	ConsoleDirectiveChannel = ":channel"
+	ConsoleDirectiveUser    = ":user"
+)
+
+// ConsoleOption can be used to customize Console.
+type ConsoleOption func(c *Console)
+
+// ConsoleUser sets identity of the user that sends commands.
+func ConsoleUser(id, username string) ConsoleOption {
+	return func(c *Console) {
+		c.user = &discordgo.User{ID: id, Username: username}
+	}
+}
+
+// ConsoleChannel sets channel by name from Config.Channels or by id.
+func ConsoleChannel(channel string) ConsoleOption {
+	return func(c *Console) {
+		c.channelID = c.resolveChannel(channel)
+	}
+}
+
+// Console is a Transport that reads commands from terminal and prints
+// everything the bot would have posted to Discord. It allows to run commands
+// locally without bot token and Discord server:
+//
+//	console := discordant.NewConsole(cfg, os.Stdin, os.Stdout, discordant.ConsoleChannel(discordant.ChannelAdmin))
+//	bot, err := discordant.New(cfg, discordant.SetTransport(console))
+//	...
+//	err = bot.Run()
+//	...
+//	err = console.Listen()
+//
+// Lines are passed through the same prefix handling, access checks and
+// handlers as Discord messages. Ignored lines are printed with the reason.
+// Directives `:channel <name|id>` and `:user <id>` switch channel and user
+// identity.
+type Console struct {
+	config     *Config
+	in         io.Reader
+	out        io.Writer
+	bot        *discordgo.User
+	user       *discordgo.User
+	channelID  string
+	dispatcher events.Dispatcher
+
+	mu     sync.Mutex
+	nextID int
+}
+
+var _ Transport = (*Console)(nil)
+
+// NewConsole creates Console over input and output. Config is used to resolve
+// channel names.
+func NewConsole(cfg *Config, in io.Reader, out io.Writer, options ...ConsoleOption) *Console {
+	c := Console{
+		config:    cfg,
+		in:        in,
+		out:       out,
+		bot:       &discordgo.User{ID: ConsoleBotID, Username: "discordant", Bot: true},
+		user:      &discordgo.User{ID: ConsoleUserID, Username: "console"},
+		channelID: ConsoleChannelID,
+	}
+
+	if cfg != nil && cfg.Channels[ChannelGeneral] != "" {
+		c.channelID = cfg.Channels[ChannelGeneral]
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &c
+}
+
+// Listen reads lines from input and sends them to the bot until input is closed.
+// Every line is handled synchronously.
+func (c *Console) Listen() error {
+	scanner := bufio.NewScanner(c.in)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, ConsoleDirectiveChannel+" "):
+			c.channelID = c.resolveChannel(strings.TrimSpace(strings.TrimPrefix(line, ConsoleDirectiveChannel)))
+			c.printf("[channel %s]\n", c.channelID)
+		case strings.HasPrefix(line, ConsoleDirectiveUser+" "):
+			fields := strings.Fields(strings.TrimPrefix(line, ConsoleDirectiveUser))
+			c.user = &discordgo.User{ID: fields[0], Username: fields[0]}
+			c.printf("[user %s]\n", c.user.ID)
+		default:
+			c.dispatcher.Dispatch(c.newMessageCreate(line))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("discordant console: %w", err)
+	}
+
+	return nil
+}
+
+// User returns console bot account for "@me" and stub user for other ids.
+func (c *Console) User(userID string, _ ...discordgo.RequestOption) (*discordgo.User, error) {
+	if userID == "@me" || userID == c.bot.ID {
+		return c.bot, nil
+	}
+
+	return &discordgo.User{ID: userID, Username: userID}, nil
+}
+
+// ChannelMessageSend prints message.
+func (c *Console) ChannelMessageSend(
+	channelID string, content string, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	c.printf("[%s] %s: %s\n", channelID, c.bot.Username, content)
+
+	return c.newMessage(channelID, content), nil
+}
+
+// ChannelMessageSendComplex prints message with files and embeds.
+func (c *Console) ChannelMessageSendComplex(
+	channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	if data.Content != "" {
+		c.printf("[%s] %s: %s\n", channelID, c.bot.Username, data.Content)
+	}
+
+	for _, embed := range data.Embeds {
+		c.printEmbed(channelID, embed)
+	}
+
+	for _, file := range data.Files {
+		content, err := io.ReadAll(file.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("discordant console: read file: %w", err)
+		}
+
+		c.printf("[%s] %s: file %s\n%s\n", channelID, c.bot.Username, file.Name, content)
+	}
+
+	return c.newMessage(channelID, data.Content), nil
+}
+
+// ChannelMessageSendEmbed prints embed.
+func (c *Console) ChannelMessageSendEmbed(
+	channelID string, embed *discordgo.MessageEmbed, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	c.printEmbed(channelID, embed)
+
+	return c.newMessage(channelID, ""), nil
+}
+
+// MessageReactionAdd prints reaction.
+func (c *Console) MessageReactionAdd(channelID, messageID, emojiID string, _ ...discordgo.RequestOption) error {
+	c.printf("[%s] %s: reaction %s to %s\n", channelID, c.bot.Username, emojiID, messageID)
+
+	return nil
+}
+
+// ChannelMessageEditComplex prints edited message.
+func (c *Console) ChannelMessageEditComplex(
+	data *discordgo.MessageEdit, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	c.printf("[%s] %s: edit %s\n", data.Channel, c.bot.Username, data.ID)
+
+	if data.Content != nil {
+		c.printf("  %s\n", *data.Content)
+	}
+
+	if data.Embeds != nil {
+		for _, embed := range *data.Embeds {
+			c.printEmbed(data.Channel, embed)
+		}
+	}
+
+	msg := discordgo.Message{ID: data.ID, ChannelID: data.Channel, Author: c.bot}
+	if data.Content != nil {
+		msg.Content = *data.Content
+	}
+
+	return &msg, nil
+}
+
+// InteractionRespond prints interaction response.
+func (c *Console) InteractionRespond(
+	interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, _ ...discordgo.RequestOption,
+) error {
+	c.printf("[%s] %s: interaction %s response\n", interaction.ChannelID, c.bot.Username, interaction.ID)
+
+	if resp.Data == nil {
+		return nil
+	}
+
+	if resp.Data.Content != "" {
+		c.printf("  %s\n", resp.Data.Content)
+	}
+
+	for _, embed := range resp.Data.Embeds {
+		c.printEmbed(interaction.ChannelID, embed)
+	}
+
+	return nil
+}
+
+// UserChannelCreate returns direct message channel with the user. Its messages
+// are printed as messages of any other channel.
+func (c *Console) UserChannelCreate(recipientID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{
+		ID:         "dm-" + recipientID,
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: recipientID}},
+	}, nil
+}
+
+// AddHandler adds event handler.
+func (c *Console) AddHandler(handler interface{}) func() {
+	return c.dispatcher.AddHandler(handler)
+}
+
+// Close does nothing.
+func (c *Console) Close() error {
+	return nil
+}
+
+func (c *Console) printEmbed(channelID string, embed *discordgo.MessageEmbed) {
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "[%s] %s: embed #%06x\n", channelID, c.bot.Username, embed.Color)
+
+	if embed.Title != "" {
+		fmt.Fprintf(&buf, "  %s\n", embed.Title)
+	}
+
+	if embed.Description != "" {
+		fmt.Fprintf(&buf, "  %s\n", embed.Description)
+	}
+
+	for _, field := range embed.Fields {
+		fmt.Fprintf(&buf, "  %s: %s\n", field.Name, field.Value)
+	}
+
+	c.printf("%s", buf.String())
+}
+
+// Ignored prints why the bot ignored the line, so operator gets feedback on
+// unknown and denied commands. It is called by Discordant.
+func (c *Console) Ignored(message *discordgo.MessageCreate, reason string) {
+	c.printf("[%s] ignored: %s\n", message.ChannelID, reason)
+}
+
+func (c *Console) printf(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = fmt.Fprintf(c.out, format, args...)
+}
+
+func (c *Console) resolveChannel(channel string) string {
+	if c.config != nil {
+		if id, ok := c.config.Channels[channel]; ok {
+			return id
+		}
+	}
+
+	return channel
+}
+
+func (c *Console) newMessageCreate(content string) *discordgo.MessageCreate {
+	msg := c.newMessage(c.channelID, content)
+	msg.Author = c.user
+	msg.Member = &discordgo.Member{GuildID: msg.GuildID, User: c.user}
+
+	return &discordgo.MessageCreate{Message: msg}
+}
+
+func (c *Console) newMessage(channelID, content string) *discordgo.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+
+	return &discordgo.Message{
+		ID:        fmt.Sprintf("2%017d", c.nextID),
+		ChannelID: channelID,
+		GuildID:   ConsoleGuildID,
+		Content:   content,
+		Author:    c.bot,
+	}
+}
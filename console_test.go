@@ -0,0 +1,57 @@
+package discordant_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestConsole(t *testing.T) {
+	cfg := discordanttest.NewConfig()
+	input := strings.NewReader(strings.Join([]string{
+		"!ping",
+		"!secret",
+		"!unknown",
+		"hello",
+		":channel admin",
+		"!secret",
+	}, "\n"))
+
+	var output strings.Builder
+
+	console := discordant.NewConsole(cfg, input, &output)
+
+	bot, err := discordant.New(cfg, discordant.SetTransport(console))
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	t.Cleanup(func() { _ = bot.Close() })
+
+	bot.GENERAL("ping", func(ctx discordant.Context) error { return ctx.Send("pong") })
+	bot.ADMIN("secret", func(ctx discordant.Context) error { return ctx.Send("granted") })
+
+	if err := bot.Run(); err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+
+	if err := console.Listen(); err != nil {
+		t.Fatalf("Listen: %s", err)
+	}
+
+	general, admin := "["+discordanttest.GeneralChannelID+"] ", "["+discordanttest.AdminChannelID+"] "
+	expected := []string{
+		general + "discordant: pong",
+		general + `ignored: access to command "secret" denied`,
+		general + "ignored: " + discordant.ErrCommandNotFound.Error(),
+		general + `ignored: no prefix "!"`,
+		"[channel " + discordanttest.AdminChannelID + "]",
+		admin + "discordant: granted",
+	}
+
+	if got, want := strings.TrimSpace(output.String()), strings.Join(expected, "\n"); got != want {
+		t.Errorf("console output:\n%s\nwant:\n%s", got, want)
+	}
+}
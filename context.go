@@ -1,14 +1,11 @@
 package discordant
 
 import (
-	"bufio"
 	"bytes"
 	ctx "context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -24,13 +21,20 @@ type Context interface {
 	Command() *Command
 	Discordant() *Discordant
 	Request() *discordgo.MessageCreate
+	RequestID() string
+	Logger() Logger
+	StdContext() ctx.Context
+	Store() Store
+	Settings() Settings
 	ChannelID() string
 	QueryString() string
 	QuerySlice() ([]string, error)
 	QueryAttachmentBodyFirst() (string, error)
 	Send(msg string, params ...string) error
 	Success() error
+	SuccessWith(msg string) error
 	Fail() error
+	FailWith(err error) error
 	JSON(rawmsg any, params ...string) error
 	JSONPretty(rawmsg any, params ...string) error
 	Embed(msg *discordgo.MessageEmbed) error
@@ -41,6 +45,9 @@ type context struct {
 	command    *Command
 	discordant *Discordant
 	request    *discordgo.MessageCreate
+	requestID  string
+	logger     Logger
+	trace      ctx.Context
 }
 
 // Command returns received command.
@@ -58,6 +65,32 @@ func (c *context) Request() *discordgo.MessageCreate {
 	return c.request
 }
 
+// RequestID returns unique id of the command invocation.
+func (c *context) RequestID() string {
+	return c.requestID
+}
+
+// Logger returns logger with command, user, guild, channel and request id fields.
+func (c *context) Logger() Logger {
+	return c.logger
+}
+
+// StdContext returns standard context that carries the current tracing span.
+// It can be used to start child spans with the Discordant tracer.
+func (c *context) StdContext() ctx.Context {
+	return c.trace
+}
+
+// Store returns Discordant key-value store.
+func (c *context) Store() Store {
+	return c.discordant.store
+}
+
+// Settings returns settings of the guild in which the message was sent.
+func (c *context) Settings() Settings {
+	return c.discordant.Settings(c.request.GuildID)
+}
+
 // ChannelID returns the ID of the channel in which the message was sent.
 func (c *context) ChannelID() string {
 	return c.request.ChannelID
@@ -192,7 +225,7 @@ func (c *context) QueryAttachmentBodyFirst() (string, error) {
 
 	uri := c.Request().Message.Attachments[0].URL
 
-	req, err := http.NewRequestWithContext(ctx.Background(), http.MethodGet, uri, http.NoBody)
+	req, err := http.NewRequestWithContext(c.trace, http.MethodGet, uri, http.NoBody)
 	if err != nil {
 		return "", err
 	}
@@ -216,36 +249,7 @@ func (c *context) QueryAttachmentBodyFirst() (string, error) {
 // 1. Send as file.
 // 2. Send as multiple messages.
 func (c *context) Send(msg string, params ...string) error {
-	// Send normal message.
-	if len([]rune(msg)) <= DiscordMaxMessageLenValidate {
-		if _, err := c.discordant.session.ChannelMessageSend(c.request.ChannelID, msg); err != nil {
-			return fmt.Errorf("discordant send: %w", err)
-		}
-
-		return nil
-	}
-
-	// Message is too big. Attach as file.
-	msg = strings.TrimPrefix(msg, "```json\n")
-	msg = strings.TrimSuffix(msg, "\n```")
-
-	var buf bytes.Buffer
-
-	fileName := "message.txt"
-
-	if len(params) > 0 {
-		fileName = params[0]
-	}
-
-	if _, err := buf.Write([]byte(msg)); err != nil {
-		return fmt.Errorf("discordant send: %w", err)
-	}
-
-	ms := &discordgo.MessageSend{Files: []*discordgo.File{
-		{Name: fileName, Reader: bufio.NewReader(&buf)},
-	}}
-
-	if _, err := c.discordant.session.ChannelMessageSendComplex(c.request.ChannelID, ms); err != nil {
+	if err := c.discordant.send(c.trace, c.request.ChannelID, msg, params...); err != nil {
 		return fmt.Errorf("discordant send: %w", err)
 	}
 
@@ -254,12 +258,12 @@ func (c *context) Send(msg string, params ...string) error {
 
 // Success sends a success response message.
 func (c *context) Success() error {
-	return c.Send(ResponseMessageSuccess)
+	return c.respond(c.successResponse(), "")
 }
 
 // Fail sends a fail response message.
 func (c *context) Fail() error {
-	return c.Send(ResponseMessageFail)
+	return c.respond(c.failResponse(), "")
 }
 
 // JSON sends a JSON response with the given message/object.
@@ -280,7 +284,7 @@ func (c *context) JSONPretty(rawmsg any, params ...string) error {
 
 // Embed sends a message with embedded data.
 func (c *context) Embed(msg *discordgo.MessageEmbed) error {
-	if _, err := c.discordant.session.ChannelMessageSendEmbed(c.ChannelID(), msg); err != nil {
+	if _, err := c.discordant.sendEmbed(c.trace, c.ChannelID(), msg); err != nil {
 		return err
 	}
 
@@ -308,31 +312,15 @@ func (c *context) Embeds(msgs []discordgo.MessageEmbed) error {
 // It handles the conversion of different input types to JSON format and sends the response.
 // The pretty parameter controls whether the JSON output is formatted with indentation.
 func (c *context) json(rawmsg any, pretty bool, params ...string) error {
-	var msg string
-
-	switch val := rawmsg.(type) {
-	case string:
-		msg = fmt.Sprintf(ResponseMessageFormatJSON, val)
-	case error:
-		msg = fmt.Sprintf(ResponseMessageFormatJSON, val.Error())
-	default:
-		var (
-			rawjson []byte
-			err     error
-		)
-
-		if pretty {
-			rawjson, err = json.MarshalIndent(val, "", "  ")
-		} else {
-			rawjson, err = json.Marshal(val)
-		}
-
-		if err != nil {
-			return fmt.Errorf("%w: %w", ErrInvalidResponseMessageType, err)
-		}
-
-		msg = fmt.Sprintf(ResponseMessageFormatJSON, string(rawjson))
+	msg, err := formatJSON(rawmsg, pretty)
+	if err != nil {
+		return err
 	}
 
 	return c.Send(msg, params...)
 }
+
+// newRequestID returns random 16 characters hex id.
+func newRequestID() string {
+	return randomHex(8)
+}
@@ -1,9 +1,13 @@
 package discordant
 
 import (
+	ctx "context"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/outdead/discordant/internal/session"
@@ -28,6 +32,8 @@ const (
 const (
 	DefaultCommandPrefix    = "!"
 	DefaultCommandDelimiter = " "
+
+	DefaultCommandDisabledMessage = "command is temporarily disabled"
 )
 
 // Response massage layouts.
@@ -42,6 +48,7 @@ const (
 	ColorGreen  = 0x0008000
 	ColorYellow = 0xffaa00
 	ColorRed    = 0xff0000
+	ColorBlue   = 0x3498db
 )
 
 var (
@@ -51,9 +58,18 @@ var (
 	// ErrEmptyPrefix is returned when discord bot prefix is empty.
 	ErrEmptyPrefix = errors.New("discord bot prefix is empty")
 
+	// ErrInvalidPrefix is returned when discord bot prefix contains whitespace.
+	ErrInvalidPrefix = errors.New("discord bot prefix is invalid")
+
 	// ErrEmptyChannelID is returned when discord channel id is empty with enabled hook.
 	ErrEmptyChannelID = errors.New("discord channel id is empty")
 
+	// ErrInvalidChannelID is returned when discord channel id is not a snowflake.
+	ErrInvalidChannelID = errors.New("discord channel id is invalid")
+
+	// ErrUnknownConfigFormat is returned when config file extension is not supported.
+	ErrUnknownConfigFormat = errors.New("unknown config format")
+
 	// ErrMessageTooLong is returned when message that has been sent to discord longer
 	// than 2000 characters.
 	ErrMessageTooLong = errors.New("discord message too long")
@@ -70,8 +86,83 @@ var (
 	// ErrInvalidResponseMessageType is returned when trying to send unknown message type.
 	ErrInvalidResponseMessageType = errors.New("invalid response message type")
 
+	// ErrInvalidResponseFormat is returned when response format doesn't have
+	// exactly one %s verb.
+	ErrInvalidResponseFormat = errors.New("invalid response format")
+
 	// ErrEmptyResponseMessage is returned when trying to send empty message.
 	ErrEmptyResponseMessage = errors.New("empty response message")
+
+	// ErrCommandDisabled is returned if received command is disabled.
+	ErrCommandDisabled = errors.New("command is disabled")
+
+	// ErrInvalidLogLevel is returned when parsing unknown log level name.
+	ErrInvalidLogLevel = errors.New("invalid log level")
+
+	// ErrUnknownChannel is returned when channel name is not found in Config.Channels.
+	ErrUnknownChannel = errors.New("unknown channel")
+
+	// ErrUnexpectedStatus is returned when HTTP server responds with unexpected status.
+	ErrUnexpectedStatus = errors.New("unexpected status")
+
+	// ErrQueueFull is returned when command can't be queued for execution.
+	ErrQueueFull = errors.New("execution queue is full")
+
+	// ErrInvalidShard is returned when shard id is out of shard count.
+	ErrInvalidShard = session.ErrInvalidShard
+
+	// ErrAlreadyOpen is returned by Run when gateway connection is already
+	// open.
+	ErrAlreadyOpen = session.ErrAlreadyOpen
+
+	// ErrSessionStartLimit is returned by Run when Discord allows less
+	// session starts than shards to open.
+	ErrSessionStartLimit = session.ErrSessionStartLimit
+
+	// ErrUnknownBot is returned when Manager has no bot with the name.
+	ErrUnknownBot = errors.New("unknown bot")
+
+	// ErrDuplicateBot is returned when bot with the name is already added to Manager.
+	ErrDuplicateBot = errors.New("duplicate bot")
+
+	// ErrUnknownAlert is returned when resolved alert is not active.
+	ErrUnknownAlert = errors.New("unknown alert")
+
+	// ErrInvalidSchedule is returned when job schedule can't be parsed.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+
+	// ErrDuplicateJob is returned when job with the name is already scheduled.
+	ErrDuplicateJob = errors.New("duplicate job")
+
+	// ErrUnknownJob is returned when job with the name is not scheduled.
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrJobNotStarted is returned when triggered job is still running or
+	// the scheduler is stopped.
+	ErrJobNotStarted = errors.New("job is not started")
+
+	// ErrJobPanic is recorded as the last job error when job function panics.
+	ErrJobPanic = errors.New("job panicked")
+
+	// ErrInvalidDuration is returned when duration can't be parsed.
+	ErrInvalidDuration = errors.New("invalid duration")
+
+	// ErrUnknownReminder is returned when reminder is not found.
+	ErrUnknownReminder = errors.New("unknown reminder")
+
+	// ErrKeyNotFound is returned when store has no value by key.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrUnknownSetting is returned when setting is not declared.
+	ErrUnknownSetting = errors.New("unknown setting")
+
+	// ErrDuplicateSetting is returned when setting with the same key is
+	// already declared.
+	ErrDuplicateSetting = errors.New("duplicate setting")
+
+	// ErrInvalidSetting is returned when setting value has wrong type or
+	// doesn't pass validation.
+	ErrInvalidSetting = errors.New("invalid setting")
 )
 
 // HandlerFunc defines a function to serve HTTP requests.
@@ -79,12 +170,32 @@ type HandlerFunc func(Context) error
 
 // Discordant represents a connection to the Discord API.
 type Discordant struct {
-	config              *Config
-	id                  string
-	session             *session.Session
-	logger              Logger
-	commands            map[string]Command
-	commandsAccessOrder []string
+	// ErrorHandler is called when command handler returns an error.
+	// DefaultErrorHandler is used if it is not set.
+	ErrorHandler ErrorHandlerFunc
+
+	state           atomic.Pointer[state]
+	id              string
+	session         Transport
+	logger          Logger
+	commands        *registry
+	successResponse Response
+	failResponse    Response
+	policy          ExecutionPolicy
+	executor        *executor
+	metrics         Metrics
+	auditSinks      []AuditSink
+	auditChannel    string
+	tracer          Tracer
+	intents         *discordgo.Intent
+	extraIntents    discordgo.Intent
+	handlerIntents  atomic.Uint64
+	runMu           sync.Mutex
+	running         bool
+	scheduler       *scheduler
+	reminders       *reminders
+	store           Store
+	settings        *settingsRegistry
 }
 
 // New creates a new Discord session and will automate some startup
@@ -92,51 +203,72 @@ type Discordant struct {
 // arguments, and it will return an empty Discord session.
 func New(cfg *Config, options ...Option) (*Discordant, error) {
 	d := Discordant{
-		config:              cfg,
-		logger:              NewDefaultLog(),
-		commands:            make(map[string]Command),
-		commandsAccessOrder: make([]string, len(cfg.AccessOrder)),
+		logger:          NewDefaultLog(),
+		metrics:         nopMetrics{},
+		tracer:          nopTracer{},
+		commands:        newRegistry(),
+		successResponse: DefaultSuccessResponse,
+		failResponse:    DefaultFailResponse,
+		store:           NewMemoryStore(),
+		settings:        newSettingsRegistry(),
 	}
 
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
+	d.ErrorHandler = d.DefaultErrorHandler
+
 	for _, option := range options {
 		option(&d)
 	}
 
+	for _, response := range []Response{d.successResponse, d.failResponse} {
+		if err := response.Validate(); err != nil {
+			return nil, fmt.Errorf("discordant: %w", err)
+		}
+	}
+
+	d.executor = newExecutor(d.policy)
+	d.scheduler = newScheduler(&d)
+
 	if d.session == nil && cfg.Token == "" {
 		return nil, ErrEmptyToken
 	}
 
 	if d.session == nil {
-		var err error
-		if d.session, err = session.New(cfg.Token); err != nil {
+		ses, err := session.New(cfg.Token)
+		if err != nil {
 			return nil, fmt.Errorf("discordant: %w", err)
 		}
-	}
 
-	user, err := d.session.User("@me")
-	if err != nil {
-		_ = d.Close()
-
-		return nil, fmt.Errorf("discordant: retrieve bot account: %w", err)
+		d.session = ses
 	}
 
-	d.id = user.ID
+	if d.id == "" {
+		user, err := d.session.User("@me")
+		if err != nil {
+			_ = d.Close()
+
+			return nil, fmt.Errorf("discordant: retrieve bot account: %w", err)
+		}
 
-	if len(d.config.AccessOrder) == 0 {
-		d.commandsAccessOrder = []string{ChannelGeneral, ChannelAdmin}
-	} else {
-		copy(d.commandsAccessOrder, d.config.AccessOrder)
+		d.id = user.ID
 	}
 
+	d.state.Store(newState(cfg))
+
 	return &d, nil
 }
 
-// Close closes discord connection.
+// Close stops scheduled jobs and reminders and closes discord connection.
 func (d *Discordant) Close() error {
+	d.scheduler.stop()
+
+	if d.reminders != nil {
+		d.reminders.stop()
+	}
+
 	if d.session != nil {
 		if err := d.session.Close(); err != nil {
 			return fmt.Errorf("discordant: close connection: %w", err)
@@ -146,14 +278,50 @@ func (d *Discordant) Close() error {
 	return nil
 }
 
-// Run runs discord bot handlers.
-func (d *Discordant) Run() {
-	for name, command := range d.commands {
-		command.Name = name
-		d.commands[name] = command
+// Run opens gateway connection if the session is created by Discordant and
+// runs discord bot handlers, scheduled jobs and reminders. Gateway intents are
+// taken from SetIntents option or computed from registered commands and
+// handlers, so Run must be called after commands and handlers registration.
+// Run does nothing if the bot is already running.
+func (d *Discordant) Run() error {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+
+	if d.running {
+		return nil
+	}
+
+	intents := d.Intents()
+
+	if ses, ok := d.session.(intentsGetter); ok && d.intents == nil {
+		// Session is opened outside Discordant. Check its intents.
+		if opened := ses.Intents(); opened != 0 {
+			intents = opened
+		}
+	}
+
+	d.checkIntents(intents)
+
+	if ses, ok := d.session.(opener); ok {
+		if err := ses.Open(intents, d.current().config.sharding()); err != nil {
+			return fmt.Errorf("discordant: %w", err)
+		}
+	}
+
+	if d.reminders != nil {
+		if err := d.reminders.start(); err != nil {
+			_ = d.session.Close()
+
+			return fmt.Errorf("discordant: %w", err)
+		}
 	}
 
 	d.AddHandler(d.commandHandler)
+	d.scheduler.start()
+
+	d.running = true
+
+	return nil
 }
 
 // ID returns stored bot id.
@@ -161,29 +329,82 @@ func (d *Discordant) ID() string {
 	return d.id
 }
 
-// Session returns discord Session.
+// Session returns discord Session. It returns nil if Discordant runs over
+// an alternative Transport.
 func (d *Discordant) Session() *discordgo.Session {
-	return d.session.Session
+	transport := d.session
+	if nc, ok := transport.(nopCloser); ok {
+		transport = nc.Transport
+	}
+
+	switch ses := transport.(type) {
+	case *session.Session:
+		return ses.Session
+	case *discordgo.Session:
+		return ses
+	default:
+		return nil
+	}
 }
 
-// Commands returns commands list.
+// Transport returns Discord API transport.
+func (d *Discordant) Transport() Transport {
+	return d.session
+}
+
+// Commands returns a copy of commands list.
 func (d *Discordant) Commands() map[string]Command {
-	return d.commands
+	return d.commands.all()
 }
 
 // AddHandler allows you to add an event handler that will be fired anytime
-// the Discord WSAPI event that matches the function fires.
+// the Discord WSAPI event that matches the function fires. Intents of the
+// event are added to required intents, so handlers should be added before Run.
 func (d *Discordant) AddHandler(handler interface{}) func() {
+	d.handlerIntents.Or(uint64(handlerIntents(handler)))
+
 	return d.session.AddHandler(handler)
 }
 
-// NewContext creates new Context.
+// NewContext creates new Context. Context logger has command, user, guild,
+// channel and request id fields.
 func (d *Discordant) NewContext(message *discordgo.MessageCreate, command *Command) Context {
-	return &context{
+	return d.newContext(nil, message, command)
+}
+
+func (d *Discordant) newContext(trace ctx.Context, message *discordgo.MessageCreate, command *Command) *context {
+	if trace == nil {
+		trace = ctx.Background()
+	}
+
+	c := context{
 		command:    command,
 		request:    message,
 		discordant: d,
+		requestID:  newRequestID(),
+		trace:      trace,
+	}
+
+	fields := Fields{
+		FieldChannel:   message.ChannelID,
+		FieldRequestID: c.requestID,
 	}
+
+	if command != nil {
+		fields[FieldCommand] = command.Name
+	}
+
+	if message.Author != nil {
+		fields[FieldUser] = message.Author.ID
+	}
+
+	if message.GuildID != "" {
+		fields[FieldGuild] = message.GuildID
+	}
+
+	c.logger = WithFields(d.logger, fields)
+
+	return &c
 }
 
 // ADMIN adds route handler to admin channel.
@@ -200,13 +421,15 @@ func (d *Discordant) GENERAL(name string, handler HandlerFunc, options ...Comman
 
 // ALL adds route handler to any channel.
 func (d *Discordant) ALL(name string, handler HandlerFunc, options ...CommandOption) {
-	options = append(options, MiddlewareAccess(d.commandsAccessOrder...))
+	options = append(options, middlewareAccessAll())
 	d.Add(name, handler, options...)
 }
 
-// Add adds route handler.
+// Add adds route handler. It is safe to add commands after Run. Command with
+// the same name is replaced.
 func (d *Discordant) Add(name string, handler HandlerFunc, options ...CommandOption) {
 	command := Command{
+		Name:   name,
 		action: handler,
 	}
 
@@ -214,50 +437,50 @@ func (d *Discordant) Add(name string, handler HandlerFunc, options ...CommandOpt
 		option(&command)
 	}
 
-	d.fixCommandAccess(&command)
+	command.declaredAccess = command.Access
+	command.Disabled = command.Disabled || d.storedDisabled(name)
 
-	d.commands[name] = command
+	// Access is computed under the registry lock, so that concurrent Reload
+	// can't leave the command with access list of previous config.
+	d.commands.set(command, func(command *Command) {
+		command.Access = d.current().commandAccess(command)
+	})
+}
+
+// Remove removes command by name.
+func (d *Discordant) Remove(name string) error {
+	return d.commands.remove(name)
+}
+
+// Disable disables command by name. Disabled command stays in commands list,
+// but it is not executed. The state is kept in Discordant Store, so command
+// added later with the same name is disabled too.
+func (d *Discordant) Disable(name string) error {
+	return d.setDisabled(name, true)
+}
+
+// Enable enables previously disabled command by name.
+func (d *Discordant) Enable(name string) error {
+	return d.setDisabled(name, false)
 }
 
 // GetCommand returns command by received message.
 func (d *Discordant) GetCommand(message string) (*Command, error) {
-	// Command without args.
-	if command, ok := d.commands[message]; ok {
-		return &command, nil
+	command, ok := d.commands.find(message)
+	if !ok {
+		return nil, ErrCommandNotFound
 	}
 
-	// Find commands with args.
-	for name, command := range d.commands {
-		if strings.Index(message, name+DefaultCommandDelimiter) == 0 {
-			// Workaround for intersecting commands such as `rules` and `rules set` or `help` and `help set`.
-			// TODO: Come up with a better solution.
-			if strings.Index(message, name+DefaultCommandDelimiter+"set ") == 0 {
-				continue
-			}
-
-			command.Arg = strings.Replace(message, name, "", 1)
-			command.Arg = strings.TrimSpace(command.Arg)
-
-			return &command, nil
-		}
+	if command.Disabled {
+		return &command, ErrCommandDisabled
 	}
 
-	return nil, ErrCommandNotFound
+	return &command, nil
 }
 
 // CheckAccess returns true if access is allowed.
 func (d *Discordant) CheckAccess(id string, channels ...string) bool {
-	if len(channels) == 0 {
-		return true
-	}
-
-	for _, channel := range channels {
-		if id == d.config.Channels[channel] {
-			return true
-		}
-	}
-
-	return false
+	return d.current().checkAccess(id, channels...)
 }
 
 func (d *Discordant) commandHandler(_ *discordgo.Session, message *discordgo.MessageCreate) {
@@ -266,63 +489,157 @@ func (d *Discordant) commandHandler(_ *discordgo.Session, message *discordgo.Mes
 		return
 	}
 
+	// Config is read once, so that a concurrent Reload can't change prefix,
+	// channels or access lists while the message is handled.
+	st := d.current()
+	cfg := st.config
+
 	// Not bot command. Do nothing.
-	if strings.Index(message.Content, d.config.Prefix) != 0 {
+	if strings.Index(message.Content, cfg.Prefix) != 0 {
+		d.ignore(message, fmt.Sprintf("no prefix %q", cfg.Prefix))
+
 		return
 	}
 
-	if d.config.Safemode {
+	if cfg.Safemode {
 		// Unknown channel. Do nothing.
-		if !d.CheckAccess(message.ChannelID, ChannelGeneral, ChannelAdmin) {
+		if !st.checkAccess(message.ChannelID, ChannelGeneral, ChannelAdmin) {
 			d.logger.Debugf("discordant: unknown channel %s", message.ChannelID)
+			d.ignore(message, "unknown channel "+message.ChannelID)
 
 			return
 		}
 	}
 
+	trace, span := d.startCommandSpan(message)
+	defer span.End()
+
 	// Remove prefix from discord message.
-	content := strings.TrimPrefix(message.Content, d.config.Prefix)
+	content := strings.TrimPrefix(message.Content, cfg.Prefix)
+
+	_, routeSpan := d.tracer.Start(trace, SpanRoute)
 
 	command, err := d.GetCommand(content)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrCommandDisabled) {
+		endSpan(routeSpan, err)
+		span.SetAttributes(Attr(AttrOutcome, OutcomeUnknown))
+
 		d.logger.Debug(err)
+		d.metrics.UnknownCommand()
+		d.ignore(message, err.Error())
 
 		return
 	}
 
-	if ok := d.CheckAccess(message.ChannelID, command.Access...); !ok {
-		d.logger.Debugf("discordant: access to command \"%s\" denied", command.Name)
+	routeSpan.End()
 
-		return
+	command.Access = st.commandAccess(command)
+
+	ctx := d.newContext(trace, message, command)
+
+	span.SetAttributes(Attr(AttrCommand, command.Name), Attr(AttrRequestID, ctx.requestID))
+
+	outcome, duration, err := d.execute(ctx, st, command, err)
+
+	span.SetAttributes(Attr(AttrOutcome, outcome))
+	endSpan(span, err)
+
+	if outcome == OutcomeDenied {
+		d.ignore(message, fmt.Sprintf("access to command %q denied", command.Name))
 	}
 
-	ctx := d.NewContext(message, command)
+	d.audit(ctx, outcome, duration, err)
+}
 
-	if err := command.action(ctx); err != nil {
-		d.logger.Errorf("discordant action: %s", err)
+// ignore reports the reason of ignored message to transport if it can show it.
+func (d *Discordant) ignore(message *discordgo.MessageCreate, reason string) {
+	transport := d.session
+	if nc, ok := transport.(nopCloser); ok {
+		transport = nc.Transport
+	}
 
-		if err := ctx.Fail(); err != nil {
-			d.logger.Errorf("send fail response: %s", err)
-		}
+	if ses, ok := transport.(ignorer); ok {
+		ses.Ignored(message, reason)
 	}
 }
 
-func (d *Discordant) fixCommandAccess(command *Command) {
-	buf := make(map[string]struct{}, len(d.commandsAccessOrder))
+// execute checks access against the config snapshot and runs command handler.
+// It returns command outcome, handler duration and handler error.
+func (d *Discordant) execute(
+	ctx *context, st *state, command *Command, lookupErr error,
+) (string, time.Duration, error) {
+	_, accessSpan := d.tracer.Start(ctx.trace, SpanAccess)
 
-	access := make([]string, 0, len(d.commandsAccessOrder))
+	if ok := st.checkAccess(ctx.ChannelID(), command.Access...); !ok {
+		accessSpan.SetAttributes(Attr(AttrOutcome, OutcomeDenied))
+		accessSpan.End()
 
-	for _, channel := range command.Access {
-		if _, ok := buf[channel]; !ok {
-			buf[channel] = struct{}{}
-		}
+		ctx.Logger().Debugf("discordant: access to command \"%s\" denied", command.Name)
+		d.metrics.AccessDenied(command.Name)
+
+		return OutcomeDenied, 0, nil
 	}
 
-	for _, channel := range d.commandsAccessOrder {
-		if _, ok := buf[channel]; ok {
-			access = append(access, channel)
-		}
+	accessSpan.End()
+
+	if lookupErr != nil {
+		ctx.Logger().Debugf("discordant: command \"%s\" is disabled", command.Name)
+		d.metrics.CommandInvoked(command.Name, OutcomeDisabled, 0)
+		d.handleError(ctx, WrapUserError(DefaultCommandDisabledMessage, lookupErr))
+
+		return OutcomeDisabled, 0, lookupErr
+	}
+
+	_, queueSpan := d.tracer.Start(ctx.trace, SpanQueue)
+
+	release, err := d.executor.acquire(command, ctx.ChannelID())
+	endSpan(queueSpan, err)
+
+	if err != nil {
+		ctx.Logger().Debugf("discordant: command \"%s\" rejected: %s", command.Name, err)
+		d.metrics.CommandInvoked(command.Name, OutcomeRejected, 0)
+		d.handleError(ctx, WrapUserError(d.executor.queueFullMessage(), err))
+
+		return OutcomeRejected, 0, err
+	}
+	defer release()
+
+	commandTrace := ctx.trace
+
+	var handlerSpan Span
+
+	ctx.trace, handlerSpan = d.tracer.Start(commandTrace, SpanHandler)
+
+	start := time.Now()
+
+	err = command.action(ctx)
+
+	endSpan(handlerSpan, err)
+
+	ctx.trace = commandTrace
+
+	if err != nil {
+		duration := time.Since(start)
+
+		d.metrics.CommandInvoked(command.Name, OutcomeError, duration)
+		d.handleError(ctx, err)
+
+		return OutcomeError, duration, err
+	}
+
+	duration := time.Since(start)
+
+	d.metrics.CommandInvoked(command.Name, OutcomeSuccess, duration)
+
+	return OutcomeSuccess, duration, nil
+}
+
+func (d *Discordant) handleError(ctx Context, err error) {
+	if d.ErrorHandler != nil {
+		d.ErrorHandler(ctx, err)
+
+		return
 	}
 
-	command.Access = access
+	d.DefaultErrorHandler(ctx, err)
 }
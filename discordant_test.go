@@ -0,0 +1,87 @@
+package discordant_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestRunTwice(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	var calls int
+
+	h.Bot.GENERAL("ping", func(c discordant.Context) error {
+		calls++
+
+		return c.Success()
+	})
+
+	if err := h.Bot.Run(); err != nil {
+		t.Fatalf("second Run: %s", err)
+	}
+
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+
+	if calls != 1 {
+		t.Errorf("command is called %d times, want 1", calls)
+	}
+
+	h.AssertMessagesCount(1)
+}
+
+func TestQueueFullResponse(t *testing.T) {
+	h := discordanttest.New(t, nil, discordant.SetExecutionPolicy(discordant.ExecutionPolicy{
+		MaxConcurrency: 1,
+		QueueSize:      1,
+	}))
+
+	started, block := make(chan struct{}, 3), make(chan struct{})
+
+	h.Bot.GENERAL("wait", func(discordant.Context) error {
+		started <- struct{}{}
+		<-block
+
+		return nil
+	})
+
+	var wg sync.WaitGroup
+
+	exec := func() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			h.Exec(discordanttest.GeneralChannelID, "!wait")
+		}()
+	}
+
+	exec()
+	<-started
+
+	// One of two invocations waits in the queue and the other is rejected.
+	exec()
+	exec()
+
+	for deadline := time.Now().Add(time.Second); len(h.Messages()) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("invocation over queue size is not rejected")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	close(block)
+	wg.Wait()
+
+	h.AssertMessagesCount(1)
+	h.AssertMessageContains(discordant.DefaultQueueFullMessage)
+
+	if len(started) != 1 {
+		t.Errorf("%d queued invocations are run, want 1", len(started))
+	}
+}
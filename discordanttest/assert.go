@@ -0,0 +1,163 @@
+package discordanttest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/outdead/discordant"
+)
+
+// Messages returns messages sent by the bot.
+func (h *Harness) Messages() []Message {
+	return h.records.Messages()
+}
+
+// Reactions returns reactions added by the bot.
+func (h *Harness) Reactions() []Reaction {
+	return h.records.Reactions()
+}
+
+// Interactions returns interaction responses sent by the bot.
+func (h *Harness) Interactions() []Interaction {
+	return h.records.Interactions()
+}
+
+// DMChannel returns id of direct message channel the bot has created with
+// the user or empty string.
+func (h *Harness) DMChannel(userID string) string {
+	return h.records.DMChannel(userID)
+}
+
+// Reset removes recorded messages, reactions and interaction responses.
+func (h *Harness) Reset() {
+	h.records.Reset()
+}
+
+// LastMessage returns the last message sent by the bot. It fails the test if
+// nothing has been sent.
+func (h *Harness) LastMessage() Message {
+	h.tb.Helper()
+
+	messages := h.Messages()
+	if len(messages) == 0 {
+		h.tb.Fatalf("discordanttest: no messages have been sent")
+	}
+
+	return messages[len(messages)-1]
+}
+
+// AssertMessage checks that the bot has sent a message with the content.
+func (h *Harness) AssertMessage(content string) {
+	h.tb.Helper()
+
+	h.assertMessage("message with content "+strconv.Quote(content), func(msg Message) bool {
+		return msg.Content == content
+	})
+}
+
+// AssertMessageContains checks that the bot has sent a message that contains substr.
+func (h *Harness) AssertMessageContains(substr string) {
+	h.tb.Helper()
+
+	h.assertMessage("message containing "+strconv.Quote(substr), func(msg Message) bool {
+		return strings.Contains(msg.Content, substr)
+	})
+}
+
+// AssertMessageIn checks that the bot has sent a message to the channel.
+func (h *Harness) AssertMessageIn(channelID string) {
+	h.tb.Helper()
+
+	h.assertMessage("message in channel "+channelID, func(msg Message) bool {
+		return msg.ChannelID == channelID
+	})
+}
+
+// AssertFile checks that the bot has sent a file with the name and content.
+func (h *Harness) AssertFile(name, content string) {
+	h.tb.Helper()
+
+	h.assertMessage("file "+strconv.Quote(name)+" with content "+strconv.Quote(content), func(msg Message) bool {
+		for _, file := range msg.Files {
+			if file.Name == name && file.Content == content {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// AssertEmbed checks that the bot has sent an embed with the title.
+func (h *Harness) AssertEmbed(title string) {
+	h.tb.Helper()
+
+	h.assertMessage("embed with title "+strconv.Quote(title), func(msg Message) bool {
+		for _, embed := range msg.Embeds {
+			if embed.Title == title {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// AssertReaction checks that the bot has added the reaction.
+func (h *Harness) AssertReaction(emoji string) {
+	h.tb.Helper()
+
+	for _, reaction := range h.Reactions() {
+		if reaction.Emoji == emoji {
+			return
+		}
+	}
+
+	h.tb.Errorf("discordanttest: expected reaction %s, got %v", strconv.Quote(emoji), h.Reactions())
+}
+
+// AssertSuccess checks that the bot has sent the default success response.
+func (h *Harness) AssertSuccess() {
+	h.tb.Helper()
+
+	h.AssertMessage(discordant.ResponseMessageSuccess)
+}
+
+// AssertFail checks that the bot has sent the default fail response.
+func (h *Harness) AssertFail() {
+	h.tb.Helper()
+
+	h.AssertMessage(discordant.ResponseMessageFail)
+}
+
+// AssertNoMessages checks that the bot has sent nothing.
+func (h *Harness) AssertNoMessages() {
+	h.tb.Helper()
+
+	if messages := h.Messages(); len(messages) != 0 {
+		h.tb.Errorf("discordanttest: expected no messages, got %d: %v", len(messages), messages)
+	}
+}
+
+// AssertMessagesCount checks the number of messages sent by the bot.
+func (h *Harness) AssertMessagesCount(count int) {
+	h.tb.Helper()
+
+	if messages := h.Messages(); len(messages) != count {
+		h.tb.Errorf("discordanttest: expected %d messages, got %d: %v", count, len(messages), messages)
+	}
+}
+
+func (h *Harness) assertMessage(expected string, match func(msg Message) bool) {
+	h.tb.Helper()
+
+	messages := h.Messages()
+
+	for _, msg := range messages {
+		if match(msg) {
+			return
+		}
+	}
+
+	h.tb.Errorf("discordanttest: expected %s, got %v", expected, messages)
+}
@@ -0,0 +1,123 @@
+package discordanttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/outdead/discordant/internal/otlp"
+)
+
+// Span is a span received by Collector.
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Attributes   map[string]interface{}
+	Error        string
+}
+
+// Collector is a local stand-in for OpenTelemetry collector that accepts
+// OTLP/HTTP JSON trace export requests.
+type Collector struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	services []string
+	spans    []Span
+}
+
+// NewCollector starts and returns a new Collector. Its URL can be passed to
+// discordant.NewOTLPTracer. The caller should call Close when finished.
+func NewCollector() *Collector {
+	c := Collector{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+otlp.TracesPath, c.handleTraces)
+
+	c.Server = httptest.NewServer(mux)
+
+	return &c
+}
+
+// Spans returns received spans.
+func (c *Collector) Spans() []Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Span(nil), c.spans...)
+}
+
+// Services returns service names of received resources.
+func (c *Collector) Services() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.services...)
+}
+
+// SpansByName returns received spans with the name.
+func (c *Collector) SpansByName(name string) []Span {
+	var spans []Span
+
+	for _, span := range c.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func (c *Collector) handleTraces(w http.ResponseWriter, r *http.Request) {
+	var req otlp.ExportTraceServiceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, resource := range req.ResourceSpans {
+		for _, attr := range resource.Resource.Attributes {
+			if attr.Key == "service.name" {
+				if name, ok := attr.Value.Value().(string); ok {
+					c.services = append(c.services, name)
+				}
+			}
+		}
+
+		for _, scope := range resource.ScopeSpans {
+			for _, span := range scope.Spans {
+				c.spans = append(c.spans, newSpan(span))
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func newSpan(span otlp.Span) Span {
+	result := Span{
+		TraceID:      span.TraceID,
+		SpanID:       span.SpanID,
+		ParentSpanID: span.ParentSpanID,
+		Name:         span.Name,
+		Attributes:   make(map[string]interface{}, len(span.Attributes)),
+	}
+
+	for _, attr := range span.Attributes {
+		result.Attributes[attr.Key] = attr.Value.Value()
+	}
+
+	if span.Status.Code == otlp.StatusCodeError {
+		result.Error = span.Status.Message
+	}
+
+	return result
+}
@@ -0,0 +1,290 @@
+// Package discordanttest provides utilities for testing Discordant command handlers
+// without Discord connection.
+//
+//	h := discordanttest.New(t, nil)
+//	h.Bot.GENERAL("ping", func(ctx discordant.Context) error {
+//		return ctx.Send("pong")
+//	})
+//
+//	h.Exec(discordanttest.GeneralChannelID, "!ping")
+//	h.AssertMessage("pong")
+package discordanttest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+)
+
+// Default channels and guild.
+const (
+	GuildID          = "700000000000000001"
+	GeneralChannelID = "700000000000000002"
+	AdminChannelID   = "700000000000000003"
+	UserID           = "700000000000000004"
+)
+
+// Request is a synthetic message that is sent to the bot.
+type Request struct {
+	Content     string
+	ChannelID   string
+	GuildID     string
+	Author      *discordgo.User
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to Request. Its content is served by Server,
+// so QueryAttachmentBodyFirst works as with real Discord.
+type Attachment struct {
+	Filename string
+	Content  string
+}
+
+// Harness is a Discordant over in-memory Session or over real discordgo session
+// pointed at local Server.
+type Harness struct {
+	Bot *discordant.Discordant
+
+	// Session is set for in-memory harness created with New.
+	Session *Session
+
+	// Server is set for end-to-end harness created with NewE2E. For in-memory
+	// harness it is started on demand to serve attachments.
+	Server *Server
+
+	tb       testing.TB
+	dispatch func(event interface{})
+	records  records
+	restore  func()
+
+	mu     sync.Mutex
+	nextID int
+}
+
+type records interface {
+	Messages() []Message
+	Reactions() []Reaction
+	Interactions() []Interaction
+	DMChannel(userID string) string
+	Reset()
+}
+
+// NewConfig returns config with default prefix and channels.
+func NewConfig() *discordant.Config {
+	return &discordant.Config{
+		Prefix: discordant.DefaultCommandPrefix,
+		Channels: map[string]string{
+			discordant.ChannelGeneral: GeneralChannelID,
+			discordant.ChannelAdmin:   AdminChannelID,
+		},
+	}
+}
+
+// New creates Harness over in-memory Session and runs bot handlers. NewConfig
+// is used if cfg is nil. Harness is closed on test cleanup.
+func New(tb testing.TB, cfg *discordant.Config, options ...discordant.Option) *Harness {
+	tb.Helper()
+
+	ses := NewSession()
+
+	h := Harness{
+		Session:  ses,
+		tb:       tb,
+		dispatch: ses.Dispatch,
+		records:  ses,
+	}
+
+	h.run(cfg, ses, options...)
+
+	return &h
+}
+
+// NewE2E creates Harness over real discordgo session that sends requests to
+// local Server and runs bot handlers. It overrides discordgo endpoints until
+// test cleanup, so tests that use it must not run in parallel.
+func NewE2E(tb testing.TB, cfg *discordant.Config, options ...discordant.Option) *Harness {
+	tb.Helper()
+
+	srv := NewServer()
+
+	h := Harness{
+		Server:  srv,
+		tb:      tb,
+		records: srv,
+		restore: srv.OverrideEndpoints(),
+	}
+
+	ses, err := srv.NewSession()
+	if err != nil {
+		h.restore()
+		srv.Close()
+		tb.Fatalf("discordanttest: %s", err)
+	}
+
+	h.dispatch = ses.Dispatch
+	h.run(cfg, ses, options...)
+
+	return &h
+}
+
+func (h *Harness) run(cfg *discordant.Config, transport discordant.Transport, options ...discordant.Option) {
+	h.tb.Helper()
+
+	if cfg == nil {
+		cfg = NewConfig()
+	}
+
+	options = append([]discordant.Option{discordant.SetTransport(transport)}, options...)
+
+	bot, err := discordant.New(cfg, options...)
+	if err != nil {
+		h.Close()
+		h.tb.Fatalf("discordanttest: create bot: %s", err)
+	}
+
+	h.Bot = bot
+
+	if err := h.Bot.Run(); err != nil {
+		h.Close()
+		h.tb.Fatalf("discordanttest: run bot: %s", err)
+	}
+
+	h.tb.Cleanup(h.Close)
+}
+
+// Close closes bot and server.
+func (h *Harness) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Bot != nil {
+		if err := h.Bot.Close(); err != nil {
+			h.tb.Errorf("discordanttest: close bot: %s", err)
+		}
+
+		h.Bot = nil
+	}
+
+	if h.restore != nil {
+		h.restore()
+		h.restore = nil
+	}
+
+	if h.Server != nil {
+		h.Server.Close()
+	}
+}
+
+// Exec sends message with content to the channel from default user.
+func (h *Harness) Exec(channelID, content string) *discordgo.MessageCreate {
+	h.tb.Helper()
+
+	return h.Dispatch(Request{ChannelID: channelID, Content: content})
+}
+
+// Dispatch sends request to the bot through the real command handler and
+// returns after the handler is finished.
+func (h *Harness) Dispatch(req Request) *discordgo.MessageCreate {
+	h.tb.Helper()
+
+	event := h.NewMessageCreate(req)
+	h.dispatch(event)
+
+	return event
+}
+
+// NewMessageCreate builds MessageCreate event from request. Empty fields are
+// filled with defaults: general channel, default guild and user.
+func (h *Harness) NewMessageCreate(req Request) *discordgo.MessageCreate {
+	h.tb.Helper()
+
+	if req.ChannelID == "" {
+		req.ChannelID = GeneralChannelID
+	}
+
+	if req.GuildID == "" {
+		req.GuildID = GuildID
+	}
+
+	if req.Author == nil {
+		req.Author = &discordgo.User{ID: UserID, Username: "user"}
+	}
+
+	msg := discordgo.Message{
+		ID:        h.newID(),
+		ChannelID: req.ChannelID,
+		GuildID:   req.GuildID,
+		Content:   req.Content,
+		Author:    req.Author,
+		Member:    &discordgo.Member{GuildID: req.GuildID, User: req.Author},
+	}
+
+	for _, attachment := range req.Attachments {
+		id := h.newID()
+
+		msg.Attachments = append(msg.Attachments, h.server().AddAttachment(id, attachment))
+	}
+
+	return &discordgo.MessageCreate{Message: &msg}
+}
+
+// Click presses message component with the custom id on the message sent by
+// the bot from default user and returns after handlers are finished.
+func (h *Harness) Click(msg Message, customID string) *discordgo.InteractionCreate {
+	h.tb.Helper()
+
+	user := &discordgo.User{ID: UserID, Username: "user"}
+
+	return h.ClickAs(msg, customID, &discordgo.Member{GuildID: GuildID, User: user})
+}
+
+// ClickAs presses message component with the custom id on the message sent
+// by the bot from the member, so that roles and permissions can be set.
+func (h *Harness) ClickAs(msg Message, customID string, member *discordgo.Member) *discordgo.InteractionCreate {
+	h.tb.Helper()
+
+	event := discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        h.newID(),
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   GuildID,
+		ChannelID: msg.ChannelID,
+		Message:   &discordgo.Message{ID: msg.ID, ChannelID: msg.ChannelID},
+		Member:    member,
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      customID,
+			ComponentType: discordgo.ButtonComponent,
+		},
+		Token: h.newID(),
+	}}
+
+	if h.Session == nil {
+		h.Server.expectInteraction(event.Interaction)
+	}
+
+	h.dispatch(&event)
+
+	return &event
+}
+
+func (h *Harness) server() *Server {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Server == nil {
+		h.Server = NewServer()
+	}
+
+	return h.Server
+}
+
+func (h *Harness) newID() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+
+	return fmt.Sprintf("6%017d", h.nextID)
+}
@@ -0,0 +1,178 @@
+package discordanttest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrUnknownMessage is returned when edited message has not been sent.
+var ErrUnknownMessage = errors.New("unknown message")
+
+// Message is a message that has been sent by the bot. Edits are applied to
+// the message in place. AllowedMentions is nil if the bot doesn't restrict
+// mentions.
+type Message struct {
+	ID              string
+	ChannelID       string
+	Content         string
+	Files           []File
+	Embeds          []*discordgo.MessageEmbed
+	Components      []discordgo.MessageComponent
+	AllowedMentions *discordgo.MessageAllowedMentions
+	Edits           int
+}
+
+// File is a file that has been attached to sent message.
+type File struct {
+	Name    string
+	Content string
+}
+
+// Reaction is a reaction that has been added by the bot.
+type Reaction struct {
+	ChannelID string
+	MessageID string
+	Emoji     string
+}
+
+// Interaction is an interaction response that has been sent by the bot.
+type Interaction struct {
+	ID       string
+	Token    string
+	Response discordgo.InteractionResponse
+}
+
+// recorder stores everything that has been sent by the bot.
+type recorder struct {
+	mu           sync.Mutex
+	nextID       int
+	messages     []Message
+	reactions    []Reaction
+	interactions []Interaction
+	dms          map[string]string
+}
+
+// Messages returns recorded messages.
+func (r *recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Message(nil), r.messages...)
+}
+
+// Reactions returns recorded reactions.
+func (r *recorder) Reactions() []Reaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Reaction(nil), r.reactions...)
+}
+
+// Interactions returns recorded interaction responses.
+func (r *recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Reset removes recorded messages, reactions and interaction responses.
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = nil
+	r.reactions = nil
+	r.interactions = nil
+}
+
+func (r *recorder) recordMessage(msg Message) Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	msg.ID = fmt.Sprintf("8%017d", r.nextID)
+	r.messages = append(r.messages, msg)
+
+	return msg
+}
+
+func (r *recorder) recordReaction(reaction Reaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reactions = append(r.reactions, reaction)
+}
+
+// editMessage applies the edit to recorded message.
+func (r *recorder) editMessage(channelID, messageID string, edit *discordgo.MessageEdit) (Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.messages {
+		msg := &r.messages[i]
+		if msg.ID != messageID || msg.ChannelID != channelID {
+			continue
+		}
+
+		if edit.Content != nil {
+			msg.Content = *edit.Content
+		}
+
+		if edit.Embeds != nil {
+			msg.Embeds = *edit.Embeds
+		}
+
+		if edit.Components != nil {
+			msg.Components = *edit.Components
+		}
+
+		msg.Edits++
+
+		return *msg, nil
+	}
+
+	return Message{}, fmt.Errorf("%w: %s", ErrUnknownMessage, messageID)
+}
+
+func (r *recorder) recordInteraction(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+}
+
+// DMChannel returns id of direct message channel with the user or empty
+// string if the bot has not created it.
+func (r *recorder) DMChannel(userID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.dms[userID]
+}
+
+// createDM returns direct message channel with the user.
+func (r *recorder) createDM(userID string) *discordgo.Channel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dms == nil {
+		r.dms = make(map[string]string)
+	}
+
+	id, ok := r.dms[userID]
+	if !ok {
+		r.nextID++
+		id = fmt.Sprintf("4%017d", r.nextID)
+		r.dms[userID] = id
+	}
+
+	return &discordgo.Channel{
+		ID:         id,
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: userID}},
+	}
+}
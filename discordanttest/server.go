@@ -0,0 +1,398 @@
+package discordanttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant/internal/events"
+)
+
+// Server is a local stand-in for the subset of Discord REST API used by
+// Discordant. A real discordgo.Session can be pointed at it with OverrideEndpoints.
+//
+// Supported endpoints:
+//   - GET users/@me and users/{id}
+//   - POST users/@me/channels
+//   - POST channels/{id}/messages with JSON or multipart body
+//   - PATCH channels/{id}/messages/{id} with JSON body
+//   - PUT channels/{id}/messages/{id}/reactions/{emoji}/@me
+//   - POST interactions/{id}/{token}/callback
+//   - GET attachments/{id}/{filename} added with AddAttachment
+type Server struct {
+	*httptest.Server
+
+	// Bot is the account returned for users/@me.
+	Bot *discordgo.User
+
+	recorder
+
+	mu          sync.Mutex
+	attachments map[string]string
+	pending     map[string]*discordgo.Message
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when
+// finished, to shut it down.
+func NewServer() *Server {
+	s := Server{
+		Bot:         &discordgo.User{ID: BotID, Username: BotUsername, Bot: true},
+		attachments: make(map[string]string),
+		pending:     make(map[string]*discordgo.Message),
+	}
+
+	prefix := "/api/v" + discordgo.APIVersion + "/"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"users/{id}", s.handleUser)
+	mux.HandleFunc("POST "+prefix+"users/@me/channels", s.handleDM)
+	mux.HandleFunc("POST "+prefix+"channels/{channel}/messages", s.handleMessage)
+	mux.HandleFunc("PATCH "+prefix+"channels/{channel}/messages/{message}", s.handleEdit)
+	mux.HandleFunc("PUT "+prefix+"channels/{channel}/messages/{message}/reactions/{emoji}/@me", s.handleReaction)
+	mux.HandleFunc("POST "+prefix+"interactions/{id}/{token}/callback", s.handleInteraction)
+	mux.HandleFunc("GET /attachments/{id}/{filename}", s.handleAttachment)
+
+	s.Server = httptest.NewServer(mux)
+
+	return &s
+}
+
+// OverrideEndpoints points discordgo REST endpoints to the server and returns
+// function that restores them. Endpoints are package level variables of
+// discordgo, so tests that use it must not run in parallel.
+func (s *Server) OverrideEndpoints() func() {
+	endpoints := []*string{
+		&discordgo.EndpointDiscord, &discordgo.EndpointAPI, &discordgo.EndpointGuilds,
+		&discordgo.EndpointChannels, &discordgo.EndpointUsers, &discordgo.EndpointGateway,
+		&discordgo.EndpointGatewayBot, &discordgo.EndpointWebhooks, &discordgo.EndpointStickers,
+		&discordgo.EndpointApplications, &discordgo.EndpointCDN, &discordgo.EndpointCDNAttachments,
+	}
+
+	saved := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		saved[i] = *endpoint
+	}
+
+	discordgo.EndpointDiscord = s.URL + "/"
+	discordgo.EndpointAPI = discordgo.EndpointDiscord + "api/v" + discordgo.APIVersion + "/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	discordgo.EndpointChannels = discordgo.EndpointAPI + "channels/"
+	discordgo.EndpointUsers = discordgo.EndpointAPI + "users/"
+	discordgo.EndpointGateway = discordgo.EndpointAPI + "gateway"
+	discordgo.EndpointGatewayBot = discordgo.EndpointGateway + "/bot"
+	discordgo.EndpointWebhooks = discordgo.EndpointAPI + "webhooks/"
+	discordgo.EndpointStickers = discordgo.EndpointAPI + "stickers/"
+	discordgo.EndpointApplications = discordgo.EndpointAPI + "applications"
+	discordgo.EndpointCDN = s.URL + "/"
+	discordgo.EndpointCDNAttachments = discordgo.EndpointCDN + "attachments/"
+
+	return func() {
+		for i, endpoint := range endpoints {
+			*endpoint = saved[i]
+		}
+	}
+}
+
+// RESTSession is a real discordgo session without gateway connection. Events
+// are dispatched to handlers with Dispatch instead of websocket.
+type RESTSession struct {
+	*discordgo.Session
+
+	dispatcher events.Dispatcher
+}
+
+// AddHandler adds event handler.
+func (s *RESTSession) AddHandler(handler interface{}) func() {
+	return s.dispatcher.AddHandler(handler)
+}
+
+// Dispatch calls registered handlers with the event synchronously.
+func (s *RESTSession) Dispatch(event interface{}) {
+	s.dispatcher.Dispatch(event)
+}
+
+// NewSession creates discordgo session that uses the server for REST requests.
+// OverrideEndpoints must be called before any request.
+func (s *Server) NewSession() (*RESTSession, error) {
+	ses, err := discordgo.New("Bot test")
+	if err != nil {
+		return nil, fmt.Errorf("discordanttest: create session: %w", err)
+	}
+
+	ses.Client = s.Client()
+
+	return &RESTSession{Session: ses}, nil
+}
+
+// AddAttachment serves attachment content and returns attachment that can be
+// used in MessageCreate event.
+func (s *Server) AddAttachment(id string, attachment Attachment) *discordgo.MessageAttachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := "/attachments/" + id + "/" + attachment.Filename
+	s.attachments[path] = attachment.Content
+
+	return &discordgo.MessageAttachment{
+		ID:       id,
+		Filename: attachment.Filename,
+		Size:     len(attachment.Content),
+		URL:      s.URL + path,
+	}
+}
+
+// expectInteraction remembers message of the interaction, so that message
+// update response is applied to it.
+func (s *Server) expectInteraction(interaction *discordgo.Interaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[interaction.ID] = interaction.Message
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "@me" || id == s.Bot.ID {
+		writeJSON(w, http.StatusOK, s.Bot)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &discordgo.User{ID: id, Username: id})
+}
+
+func (s *Server) handleDM(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		RecipientID string `json:"recipient_id"`
+	}
+
+	if _, err := decodeBody(r, &data); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.createDM(data.RecipientID))
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Content         string                            `json:"content"`
+		Embeds          []*discordgo.MessageEmbed         `json:"embeds"`
+		Components      components                        `json:"components"`
+		AllowedMentions *discordgo.MessageAllowedMentions `json:"allowed_mentions"`
+	}
+
+	files, err := decodeBody(r, &data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	msg := s.recordMessage(Message{
+		ChannelID:       r.PathValue("channel"),
+		Content:         data.Content,
+		Files:           files,
+		Embeds:          data.Embeds,
+		Components:      data.Components,
+		AllowedMentions: data.AllowedMentions,
+	})
+
+	writeJSON(w, http.StatusOK, s.message(msg))
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Content    *string                    `json:"content"`
+		Embeds     *[]*discordgo.MessageEmbed `json:"embeds"`
+		Components *components                `json:"components"`
+	}
+
+	if _, err := decodeBody(r, &data); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	edit := discordgo.MessageEdit{Content: data.Content, Embeds: data.Embeds}
+	if data.Components != nil {
+		edit.Components = (*[]discordgo.MessageComponent)(data.Components)
+	}
+
+	msg, err := s.editMessage(r.PathValue("channel"), r.PathValue("message"), &edit)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.message(msg))
+}
+
+func (s *Server) handleReaction(w http.ResponseWriter, r *http.Request) {
+	s.recordReaction(Reaction{
+		ChannelID: r.PathValue("channel"),
+		MessageID: r.PathValue("message"),
+		Emoji:     r.PathValue("emoji"),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data *struct {
+			Content    string                    `json:"content"`
+			Embeds     []*discordgo.MessageEmbed `json:"embeds"`
+			Components components                `json:"components"`
+			Flags      discordgo.MessageFlags    `json:"flags"`
+		} `json:"data"`
+	}
+
+	if _, err := decodeBody(r, &data); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	resp := discordgo.InteractionResponse{Type: data.Type}
+	if data.Data != nil {
+		resp.Data = &discordgo.InteractionResponseData{
+			Content:    data.Data.Content,
+			Embeds:     data.Data.Embeds,
+			Components: data.Data.Components,
+			Flags:      data.Data.Flags,
+		}
+	}
+
+	s.recordInteraction(Interaction{
+		ID:       r.PathValue("id"),
+		Token:    r.PathValue("token"),
+		Response: resp,
+	})
+
+	s.mu.Lock()
+	msg := s.pending[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if resp.Type == discordgo.InteractionResponseUpdateMessage && msg != nil && resp.Data != nil {
+		if _, err := s.editMessage(msg.ChannelID, msg.ID, &discordgo.MessageEdit{
+			Content:    &resp.Data.Content,
+			Embeds:     &resp.Data.Embeds,
+			Components: &resp.Data.Components,
+		}); err != nil {
+			writeError(w, http.StatusNotFound, err)
+
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAttachment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	content, ok := s.attachments[r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	_, _ = io.WriteString(w, content)
+}
+
+// components decodes message components that are interfaces in discordgo.
+type components []discordgo.MessageComponent
+
+func (c *components) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	*c = make(components, 0, len(raws))
+
+	for _, raw := range raws {
+		component, err := discordgo.MessageComponentFromJSON(raw)
+		if err != nil {
+			return err
+		}
+
+		*c = append(*c, component)
+	}
+
+	return nil
+}
+
+// decodeBody decodes JSON body or multipart body with payload_json and files.
+func decodeBody(r *http.Request, data interface{}) ([]File, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, json.NewDecoder(r.Body).Decode(data)
+	}
+
+	var files []File
+
+	reader := multipart.NewReader(r.Body, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return files, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == "payload_json" {
+			if err := json.Unmarshal(content, data); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		files = append(files, File{Name: part.FileName(), Content: string(content)})
+	}
+}
+
+func (s *Server) message(msg Message) *discordgo.Message {
+	return &discordgo.Message{
+		ID:         msg.ID,
+		ChannelID:  msg.ChannelID,
+		Content:    msg.Content,
+		Embeds:     msg.Embeds,
+		Components: msg.Components,
+		Author:     s.Bot,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]interface{}{"code": 0, "message": err.Error()})
+}
@@ -0,0 +1,45 @@
+package discordanttest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestE2E(t *testing.T) {
+	h := discordanttest.NewE2E(t, nil, discordant.SetSuccessResponse(discordant.ReactionResponse("👍")))
+
+	h.Bot.GENERAL("ping", func(c discordant.Context) error { return c.Send("pong") })
+	h.Bot.GENERAL("long", func(c discordant.Context) error { return c.Send(strings.Repeat("a", 2500), "long.txt") })
+	h.Bot.GENERAL("ok", func(c discordant.Context) error { return c.Success() })
+	h.Bot.GENERAL("cat", func(c discordant.Context) error {
+		body, err := c.QueryAttachmentBodyFirst()
+		if err != nil {
+			return err
+		}
+
+		return c.Send("got " + body)
+	})
+
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessage("pong")
+	h.AssertMessageIn(discordanttest.GeneralChannelID)
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!long")
+	h.AssertFile("long.txt", strings.Repeat("a", 2500))
+
+	h.Reset()
+	h.Dispatch(discordanttest.Request{
+		Content:     "!cat",
+		Attachments: []discordanttest.Attachment{{Filename: "note.txt", Content: "hello"}},
+	})
+	h.AssertMessage("got hello")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!ok")
+	h.AssertReaction("👍")
+	h.AssertNoMessages()
+}
@@ -0,0 +1,192 @@
+package discordanttest
+
+import (
+	"io"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/internal/events"
+)
+
+// Default bot account.
+const (
+	BotID       = "900000000000000001"
+	BotUsername = "discordant"
+)
+
+// Session is an in-memory discordant.Transport. It dispatches events to
+// registered handlers synchronously and records everything that has been sent.
+type Session struct {
+	// Bot is the account returned for "@me".
+	Bot *discordgo.User
+
+	recorder
+	dispatcher events.Dispatcher
+	sendErr    error // guarded by recorder mu
+}
+
+var _ discordant.Transport = (*Session)(nil)
+
+// NewSession creates new in-memory Session.
+func NewSession() *Session {
+	return &Session{
+		Bot: &discordgo.User{ID: BotID, Username: BotUsername, Bot: true},
+	}
+}
+
+// User returns bot account for "@me" and stub user for other ids.
+func (s *Session) User(userID string, _ ...discordgo.RequestOption) (*discordgo.User, error) {
+	if userID == "@me" || userID == s.Bot.ID {
+		return s.Bot, nil
+	}
+
+	return &discordgo.User{ID: userID, Username: userID}, nil
+}
+
+// ChannelMessageSend records message.
+func (s *Session) ChannelMessageSend(
+	channelID string, content string, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	return s.record(Message{ChannelID: channelID, Content: content})
+}
+
+// ChannelMessageSendComplex records message with files and embeds.
+func (s *Session) ChannelMessageSendComplex(
+	channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	msg := Message{
+		ChannelID:       channelID,
+		Content:         data.Content,
+		Embeds:          data.Embeds,
+		Components:      data.Components,
+		AllowedMentions: data.AllowedMentions,
+	}
+
+	for _, file := range data.Files {
+		content, err := io.ReadAll(file.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Files = append(msg.Files, File{Name: file.Name, Content: string(content)})
+	}
+
+	return s.record(msg)
+}
+
+// ChannelMessageSendEmbed records message with embed.
+func (s *Session) ChannelMessageSendEmbed(
+	channelID string, embed *discordgo.MessageEmbed, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	return s.record(Message{ChannelID: channelID, Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+// MessageReactionAdd records reaction.
+func (s *Session) MessageReactionAdd(channelID, messageID, emojiID string, _ ...discordgo.RequestOption) error {
+	if err := s.err(); err != nil {
+		return err
+	}
+
+	s.recordReaction(Reaction{ChannelID: channelID, MessageID: messageID, Emoji: emojiID})
+
+	return nil
+}
+
+// ChannelMessageEditComplex applies the edit to recorded message.
+func (s *Session) ChannelMessageEditComplex(
+	data *discordgo.MessageEdit, _ ...discordgo.RequestOption,
+) (*discordgo.Message, error) {
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.editMessage(data.Channel, data.ID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.message(msg), nil
+}
+
+// InteractionRespond records interaction response. Message update response
+// is also applied to the interaction message.
+func (s *Session) InteractionRespond(
+	interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, _ ...discordgo.RequestOption,
+) error {
+	if err := s.err(); err != nil {
+		return err
+	}
+
+	s.recordInteraction(Interaction{ID: interaction.ID, Token: interaction.Token, Response: *resp})
+
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || interaction.Message == nil || resp.Data == nil {
+		return nil
+	}
+
+	_, err := s.editMessage(interaction.Message.ChannelID, interaction.Message.ID, &discordgo.MessageEdit{
+		Content:    &resp.Data.Content,
+		Embeds:     &resp.Data.Embeds,
+		Components: &resp.Data.Components,
+	})
+
+	return err
+}
+
+// UserChannelCreate returns direct message channel with the user.
+func (s *Session) UserChannelCreate(recipientID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+
+	return s.createDM(recipientID), nil
+}
+
+// AddHandler adds event handler.
+func (s *Session) AddHandler(handler interface{}) func() {
+	return s.dispatcher.AddHandler(handler)
+}
+
+// Close does nothing.
+func (s *Session) Close() error {
+	return nil
+}
+
+// Dispatch calls registered handlers with the event synchronously.
+func (s *Session) Dispatch(event interface{}) {
+	s.dispatcher.Dispatch(event)
+}
+
+// SetErr sets error returned by all send functions. Nil error restores
+// sending.
+func (s *Session) SetErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sendErr = err
+}
+
+func (s *Session) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sendErr
+}
+
+func (s *Session) record(msg Message) (*discordgo.Message, error) {
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+
+	return s.message(s.recordMessage(msg)), nil
+}
+
+func (s *Session) message(msg Message) *discordgo.Message {
+	return &discordgo.Message{
+		ID:         msg.ID,
+		ChannelID:  msg.ChannelID,
+		Content:    msg.Content,
+		Embeds:     msg.Embeds,
+		Components: msg.Components,
+		Author:     s.Bot,
+	}
+}
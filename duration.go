@@ -0,0 +1,132 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Duration units accepted by ParseDuration.
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses human-friendly duration such as `2h`, `1h30m`,
+// `3 days`, `1 week 2 days` or `90 minutes`.
+func ParseDuration(s string) (time.Duration, error) {
+	d, rest, err := cutDuration(strings.Fields(s))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rest) != 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	return d, nil
+}
+
+// cutDuration parses duration from the leading words and returns the rest.
+func cutDuration(words []string) (time.Duration, []string, error) {
+	var total time.Duration
+
+	i := 0
+
+	for i < len(words) {
+		word := strings.ToLower(words[i])
+
+		// Number followed by unit word such as `2 hours`.
+		if n, err := strconv.Atoi(word); (err == nil || errors.Is(err, strconv.ErrRange)) && i+1 < len(words) {
+			if unit, ok := durationUnits[strings.ToLower(words[i+1])]; ok {
+				if total, ok = addDuration(total, n, unit); !ok || err != nil {
+					return 0, words, fmt.Errorf("%w: %q is too long", ErrInvalidDuration, strings.Join(words, " "))
+				}
+
+				i += 2
+
+				continue
+			}
+		}
+
+		d, ok, err := parseCompactDuration(word)
+		if err != nil {
+			return 0, words, err
+		}
+
+		if !ok {
+			break
+		}
+
+		if total, ok = addDuration(total, 1, d); !ok {
+			return 0, words, fmt.Errorf("%w: %q is too long", ErrInvalidDuration, strings.Join(words, " "))
+		}
+
+		i++
+	}
+
+	if i == 0 || total <= 0 {
+		return 0, words, fmt.Errorf("%w: %q", ErrInvalidDuration, strings.Join(words, " "))
+	}
+
+	return total, words[i:], nil
+}
+
+// parseCompactDuration parses word such as `2h` or `1h30m`. It returns false
+// if the word is not a duration.
+func parseCompactDuration(word string) (time.Duration, bool, error) {
+	var total time.Duration
+
+	for rest := word; rest != ""; {
+		digits := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) })
+		if digits <= 0 {
+			return 0, false, nil
+		}
+
+		n, err := strconv.Atoi(rest[:digits])
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, false, fmt.Errorf("%w: %q is too long", ErrInvalidDuration, word)
+		}
+
+		if err != nil {
+			return 0, false, nil
+		}
+
+		rest = rest[digits:]
+
+		letters := strings.IndexFunc(rest, unicode.IsDigit)
+		if letters < 0 {
+			letters = len(rest)
+		}
+
+		unit, ok := durationUnits[rest[:letters]]
+		if !ok {
+			return 0, false, nil
+		}
+
+		if total, ok = addDuration(total, n, unit); !ok {
+			return 0, false, fmt.Errorf("%w: %q is too long", ErrInvalidDuration, word)
+		}
+
+		rest = rest[letters:]
+	}
+
+	return total, total > 0, nil
+}
+
+// addDuration adds n units to total. It returns false if n is negative or
+// the result overflows time.Duration.
+func addDuration(total time.Duration, n int, unit time.Duration) (time.Duration, bool) {
+	if n < 0 || time.Duration(n) > (math.MaxInt64-total)/unit {
+		return 0, false
+	}
+
+	return total + time.Duration(n)*unit, true
+}
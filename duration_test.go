@@ -0,0 +1,67 @@
+package discordant_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"2h", 2 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+		{"45s", 45 * time.Second},
+		{"3d", 72 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"90 minutes", 90 * time.Minute},
+		{"3 days", 72 * time.Hour},
+		{"1 Week 2 days", 9 * 24 * time.Hour},
+		{"1 hour 30m", 90 * time.Minute},
+		{"2562047h", 2562047 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := discordant.ParseDuration(tt.in)
+		if err != nil {
+			t.Errorf("ParseDuration(%q) error: %s", tt.in, err)
+
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDurationErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"soon",
+		"2",
+		"2 potatoes",
+		"2h later",
+		"0h",
+		"-1 hours",
+		"1x",
+		"2562048h",
+		"106752 days",
+		"9223372036854775807 weeks",
+		"99999999999999999999 hours",
+		"99999999999999999999h",
+		"2562047h 2562047h",
+		"1h 9223372036854775807w",
+	}
+
+	for _, in := range tests {
+		got, err := discordant.ParseDuration(in)
+		if !errors.Is(err, discordant.ErrInvalidDuration) {
+			t.Errorf("ParseDuration(%q) = %s, %v, want ErrInvalidDuration", in, got, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package discordant
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrorHandlerFunc defines a function to handle errors returned by command handlers.
+type ErrorHandlerFunc func(Context, error)
+
+// UserError is an error that is safe to show to the user who sent the command.
+// Message is sent to the channel as is. If Embed is set it is sent instead of
+// Message. Err holds the underlying cause, which is logged but never sent.
+type UserError struct {
+	Message string
+	Embed   *discordgo.MessageEmbed
+	Err     error
+}
+
+// NewUserError creates a new UserError with user-facing message.
+func NewUserError(message string) *UserError {
+	return &UserError{Message: message}
+}
+
+// WrapUserError creates a new UserError with user-facing message and the internal cause.
+func WrapUserError(message string, err error) *UserError {
+	return &UserError{Message: message, Err: err}
+}
+
+// WithEmbed sets embed to be sent instead of plain message.
+func (e *UserError) WithEmbed(embed *discordgo.MessageEmbed) *UserError {
+	e.Embed = embed
+
+	return e
+}
+
+// Error implements error interface. The result contains the internal cause and
+// must not be sent to the user.
+func (e *UserError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+// Unwrap returns the internal cause.
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultErrorHandler is the default error handler. User errors are logged with
+// debug level and their message is sent to the channel with the fail response
+// template. Any other error is logged with error level by FailWith and only
+// a fail response is sent, so that internal details don't leak to the channel.
+func (d *Discordant) DefaultErrorHandler(ctx Context, err error) {
+	var userErr *UserError
+	if errors.As(err, &userErr) {
+		ctx.Logger().Debugf("discordant action: %s", err)
+	}
+
+	if err := ctx.FailWith(err); err != nil {
+		ctx.Logger().Errorf("send fail response: %s", err)
+	}
+}
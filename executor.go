@@ -0,0 +1,227 @@
+package discordant
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueFullMessage is sent to the user when execution queue is full.
+const DefaultQueueFullMessage = "too many commands in progress, try again later"
+
+// SerialScope describes which invocations of a command are executed one at a time.
+type SerialScope int
+
+// Serial scopes.
+const (
+	// SerialDefault inherits serial scope from ExecutionPolicy.
+	SerialDefault SerialScope = iota
+
+	// SerialNone allows concurrent execution.
+	SerialNone
+
+	// SerialChannel allows only one command at a time per channel.
+	SerialChannel
+
+	// SerialCommand allows only one invocation of the command at a time.
+	SerialCommand
+)
+
+// ExecutionPolicy describes how command handlers are executed. Zero value runs
+// all handlers concurrently without limits.
+type ExecutionPolicy struct {
+	// MaxConcurrency limits the number of handlers running at once.
+	// Zero means no limit.
+	MaxConcurrency int
+
+	// QueueSize limits the number of invocations waiting for execution.
+	// Invocations over the limit are rejected with ErrQueueFull. Zero means no limit.
+	QueueSize int
+
+	// Serial sets default serial scope for commands without MiddlewareSerial.
+	Serial SerialScope
+
+	// QueueFullMessage is sent to the user when the invocation is rejected.
+	// DefaultQueueFullMessage is used if it is empty.
+	QueueFullMessage string
+}
+
+// MiddlewareSerial sets serial scope to command.
+func MiddlewareSerial(scope SerialScope) CommandOption {
+	return func(c *Command) {
+		c.serial = scope
+	}
+}
+
+// executor limits concurrent execution of command handlers according to
+// ExecutionPolicy. Blocked invocations wait in the discordgo event goroutine.
+type executor struct {
+	policy  ExecutionPolicy
+	slots   chan struct{}
+	pending atomic.Int64
+
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newExecutor(policy ExecutionPolicy) *executor {
+	e := executor{
+		policy: policy,
+		locks:  make(map[string]*keyLock),
+	}
+
+	if policy.MaxConcurrency > 0 {
+		e.slots = make(chan struct{}, policy.MaxConcurrency)
+	}
+
+	return &e
+}
+
+// acquire blocks until the command can be executed in the channel and returns
+// release function. It returns ErrQueueFull if the waiting queue is full.
+func (e *executor) acquire(command *Command, channelID string) (func(), error) {
+	var waiting bool
+
+	defer func() {
+		if waiting {
+			e.pending.Add(-1)
+		}
+	}()
+
+	wait := func() error {
+		if waiting {
+			return nil
+		}
+
+		if err := e.enqueue(); err != nil {
+			return err
+		}
+
+		waiting = true
+
+		return nil
+	}
+
+	key := e.key(command, channelID)
+
+	var lock *keyLock
+
+	if key != "" {
+		lock = e.lock(key)
+
+		select {
+		case lock.ch <- struct{}{}:
+		default:
+			if err := wait(); err != nil {
+				e.unlock(key, lock)
+
+				return nil, err
+			}
+
+			lock.ch <- struct{}{}
+		}
+	}
+
+	if e.slots != nil {
+		select {
+		case e.slots <- struct{}{}:
+		default:
+			if err := wait(); err != nil {
+				if lock != nil {
+					<-lock.ch
+					e.unlock(key, lock)
+				}
+
+				return nil, err
+			}
+
+			e.slots <- struct{}{}
+		}
+	}
+
+	release := func() {
+		if e.slots != nil {
+			<-e.slots
+		}
+
+		if lock != nil {
+			<-lock.ch
+			e.unlock(key, lock)
+		}
+	}
+
+	return release, nil
+}
+
+func (e *executor) enqueue() error {
+	if e.policy.QueueSize <= 0 {
+		e.pending.Add(1)
+
+		return nil
+	}
+
+	for {
+		pending := e.pending.Load()
+		if pending >= int64(e.policy.QueueSize) {
+			return ErrQueueFull
+		}
+
+		if e.pending.CompareAndSwap(pending, pending+1) {
+			return nil
+		}
+	}
+}
+
+func (e *executor) key(command *Command, channelID string) string {
+	scope := command.serial
+	if scope == SerialDefault {
+		scope = e.policy.Serial
+	}
+
+	switch scope {
+	case SerialChannel:
+		return "channel:" + channelID
+	case SerialCommand:
+		return "command:" + command.Name
+	default:
+		return ""
+	}
+}
+
+func (e *executor) lock(key string) *keyLock {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lock, ok := e.locks[key]
+	if !ok {
+		lock = &keyLock{ch: make(chan struct{}, 1)}
+		e.locks[key] = lock
+	}
+
+	lock.refs++
+
+	return lock
+}
+
+func (e *executor) unlock(key string, lock *keyLock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lock.refs--
+
+	if lock.refs == 0 {
+		delete(e.locks, key)
+	}
+}
+
+func (e *executor) queueFullMessage() string {
+	if e.policy.QueueFullMessage != "" {
+		return e.policy.QueueFullMessage
+	}
+
+	return DefaultQueueFullMessage
+}
@@ -0,0 +1,193 @@
+package discordant
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync acquires the executor in a goroutine. The returned channel
+// receives release function when the invocation is allowed to run.
+func acquireAsync(t *testing.T, e *executor, command *Command, channelID string) <-chan func() {
+	t.Helper()
+
+	acquired := make(chan func(), 1)
+
+	go func() {
+		release, err := e.acquire(command, channelID)
+		if err != nil {
+			t.Errorf("acquire: %s", err)
+
+			return
+		}
+
+		acquired <- release
+	}()
+
+	return acquired
+}
+
+// waitPending waits until n invocations are waiting in the queue.
+func waitPending(t *testing.T, e *executor, n int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for e.pending.Load() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d invocations are queued, want %d", e.pending.Load(), n)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, acquired <-chan func()) func() {
+	t.Helper()
+
+	select {
+	case release := <-acquired:
+		return release
+	case <-time.After(time.Second):
+		t.Fatal("invocation is not started")
+
+		return nil
+	}
+}
+
+func assertBlocked(t *testing.T, acquired <-chan func()) {
+	t.Helper()
+
+	select {
+	case <-acquired:
+		t.Fatal("invocation is started while the previous one is running")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestExecutorSerial(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ExecutionPolicy
+		scope   SerialScope
+		other   *Command
+		channel string
+	}{
+		{
+			name:    "command",
+			scope:   SerialCommand,
+			other:   &Command{Name: "pong"},
+			channel: "1",
+		},
+		{
+			name:    "channel from policy",
+			policy:  ExecutionPolicy{Serial: SerialChannel},
+			other:   &Command{Name: "ping"},
+			channel: "2",
+		},
+		{
+			name:    "channel from middleware",
+			policy:  ExecutionPolicy{Serial: SerialCommand},
+			scope:   SerialChannel,
+			other:   &Command{Name: "ping"},
+			channel: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newExecutor(tt.policy)
+
+			command := &Command{Name: "ping"}
+			MiddlewareSerial(tt.scope)(command)
+
+			if tt.scope != SerialDefault {
+				MiddlewareSerial(tt.scope)(tt.other)
+			}
+
+			release := receive(t, acquireAsync(t, e, command, "1"))
+
+			second := acquireAsync(t, e, command, "1")
+			waitPending(t, e, 1)
+			assertBlocked(t, second)
+
+			// Invocation with another key runs concurrently.
+			receive(t, acquireAsync(t, e, tt.other, tt.channel))()
+
+			release()
+			receive(t, second)()
+
+			if len(e.locks) != 0 {
+				t.Errorf("%d locks are left after release", len(e.locks))
+			}
+		})
+	}
+}
+
+func TestExecutorMaxConcurrency(t *testing.T) {
+	e := newExecutor(ExecutionPolicy{MaxConcurrency: 2})
+	command := &Command{Name: "ping"}
+
+	first := receive(t, acquireAsync(t, e, command, "1"))
+	second := receive(t, acquireAsync(t, e, command, "2"))
+
+	third := acquireAsync(t, e, command, "3")
+	waitPending(t, e, 1)
+	assertBlocked(t, third)
+
+	first()
+	receive(t, third)()
+	second()
+}
+
+func TestExecutorFIFO(t *testing.T) {
+	e := newExecutor(ExecutionPolicy{MaxConcurrency: 1})
+	command := &Command{Name: "ping"}
+
+	release := receive(t, acquireAsync(t, e, command, "1"))
+
+	queue := make([]<-chan func(), 4)
+	for i := range queue {
+		queue[i] = acquireAsync(t, e, command, "1")
+		waitPending(t, e, int64(i+1))
+	}
+
+	for i, acquired := range queue {
+		release()
+
+		release = receive(t, acquired)
+
+		for _, next := range queue[i+1:] {
+			assertBlocked(t, next)
+		}
+	}
+
+	release()
+}
+
+func TestExecutorQueueSize(t *testing.T) {
+	e := newExecutor(ExecutionPolicy{MaxConcurrency: 1, QueueSize: 1, Serial: SerialChannel})
+	command := &Command{Name: "ping"}
+
+	release := receive(t, acquireAsync(t, e, command, "1"))
+
+	queued := acquireAsync(t, e, command, "2")
+	waitPending(t, e, 1)
+
+	for _, channelID := range []string{"1", "3"} {
+		if _, err := e.acquire(command, channelID); !errors.Is(err, ErrQueueFull) {
+			t.Errorf("acquire in channel %s over queue size: %v, want ErrQueueFull", channelID, err)
+		}
+	}
+
+	release()
+	receive(t, queued)()
+
+	if len(e.locks) != 0 {
+		t.Errorf("%d locks are left after rejection", len(e.locks))
+	}
+
+	if pending := e.pending.Load(); pending != 0 {
+		t.Errorf("%d invocations are pending", pending)
+	}
+}
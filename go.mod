@@ -2,7 +2,10 @@ module github.com/outdead/discordant
 
 go 1.23.3
 
-require github.com/bwmarrin/discordgo v0.28.1
+require (
+	github.com/bwmarrin/discordgo v0.28.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/gorilla/websocket v1.5.3 // indirect
@@ -0,0 +1,109 @@
+package discordant
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Intents required by prefix commands.
+const IntentsCommands = discordgo.IntentGuilds |
+	discordgo.IntentGuildMessages |
+	discordgo.IntentDirectMessages |
+	discordgo.IntentMessageContent
+
+// IntentsPrivileged are intents that must be enabled in the Developer Portal
+// and need verification for bots in more than 100 guilds.
+const IntentsPrivileged = discordgo.IntentGuildMembers |
+	discordgo.IntentGuildPresences |
+	discordgo.IntentMessageContent
+
+// eventIntents are intents Discord requires to send events of the type.
+var eventIntents = map[reflect.Type]discordgo.Intent{
+	reflect.TypeOf((*discordgo.MessageCreate)(nil)):            discordgo.IntentGuildMessages | discordgo.IntentDirectMessages,
+	reflect.TypeOf((*discordgo.MessageUpdate)(nil)):            discordgo.IntentGuildMessages | discordgo.IntentDirectMessages,
+	reflect.TypeOf((*discordgo.MessageDelete)(nil)):            discordgo.IntentGuildMessages | discordgo.IntentDirectMessages,
+	reflect.TypeOf((*discordgo.MessageDeleteBulk)(nil)):        discordgo.IntentGuildMessages,
+	reflect.TypeOf((*discordgo.MessageReactionAdd)(nil)):       discordgo.IntentGuildMessageReactions | discordgo.IntentDirectMessageReactions,
+	reflect.TypeOf((*discordgo.MessageReactionRemove)(nil)):    discordgo.IntentGuildMessageReactions | discordgo.IntentDirectMessageReactions,
+	reflect.TypeOf((*discordgo.MessageReactionRemoveAll)(nil)): discordgo.IntentGuildMessageReactions | discordgo.IntentDirectMessageReactions,
+	reflect.TypeOf((*discordgo.TypingStart)(nil)):              discordgo.IntentGuildMessageTyping | discordgo.IntentDirectMessageTyping,
+	reflect.TypeOf((*discordgo.GuildMemberAdd)(nil)):           discordgo.IntentGuildMembers,
+	reflect.TypeOf((*discordgo.GuildMemberUpdate)(nil)):        discordgo.IntentGuildMembers,
+	reflect.TypeOf((*discordgo.GuildMemberRemove)(nil)):        discordgo.IntentGuildMembers,
+	reflect.TypeOf((*discordgo.PresenceUpdate)(nil)):           discordgo.IntentGuildPresences,
+	reflect.TypeOf((*discordgo.GuildBanAdd)(nil)):              discordgo.IntentGuildModeration,
+	reflect.TypeOf((*discordgo.GuildBanRemove)(nil)):           discordgo.IntentGuildModeration,
+	reflect.TypeOf((*discordgo.GuildEmojisUpdate)(nil)):        discordgo.IntentGuildEmojis,
+	reflect.TypeOf((*discordgo.InviteCreate)(nil)):             discordgo.IntentGuildInvites,
+	reflect.TypeOf((*discordgo.InviteDelete)(nil)):             discordgo.IntentGuildInvites,
+	reflect.TypeOf((*discordgo.VoiceStateUpdate)(nil)):         discordgo.IntentGuildVoiceStates,
+	reflect.TypeOf((*discordgo.WebhooksUpdate)(nil)):           discordgo.IntentGuildWebhooks,
+}
+
+// handlerIntents returns intents required by discordgo event handler such as
+// func(*discordgo.Session, *discordgo.MessageReactionAdd).
+func handlerIntents(handler interface{}) discordgo.Intent {
+	typ := reflect.TypeOf(handler)
+	if typ == nil || typ.Kind() != reflect.Func || typ.NumIn() != 2 {
+		return 0
+	}
+
+	return eventIntents[typ.In(1)]
+}
+
+// Intents returns gateway intents the bot opens connection with. If intents
+// are not set with SetIntents option, minimal intents are computed from the
+// registered features.
+func (d *Discordant) Intents() discordgo.Intent {
+	if d.intents != nil {
+		return *d.intents
+	}
+
+	return d.RequiredIntents()
+}
+
+// RequiredIntents computes minimal intents from the registered features:
+// guilds, messages with message content if prefix commands are added, intents
+// of events handled by handlers added with AddHandler (for example, guild
+// members for GuildMemberAdd) and intents added with AddIntents option.
+func (d *Discordant) RequiredIntents() discordgo.Intent {
+	intents := discordgo.IntentGuilds | discordgo.Intent(d.handlerIntents.Load()) | d.extraIntents
+
+	if len(d.commands.all()) != 0 {
+		intents |= IntentsCommands
+	}
+
+	return intents
+}
+
+// checkIntents warns when required privileged intents are missing.
+func (d *Discordant) checkIntents(intents discordgo.Intent) {
+	missing := d.RequiredIntents() &^ intents & IntentsPrivileged
+	if missing == 0 {
+		return
+	}
+
+	d.logger.Warningf("discordant: missing privileged intents: %s", intentNames(missing))
+}
+
+func intentNames(intents discordgo.Intent) string {
+	names := map[discordgo.Intent]string{
+		discordgo.IntentGuildMembers:   "guild members",
+		discordgo.IntentGuildPresences: "guild presences",
+		discordgo.IntentMessageContent: "message content",
+	}
+
+	var result []string
+
+	for _, intent := range []discordgo.Intent{
+		discordgo.IntentGuildMembers, discordgo.IntentGuildPresences, discordgo.IntentMessageContent,
+	} {
+		if intents&intent != 0 {
+			result = append(result, names[intent])
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,74 @@
+package discordant_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestIntents(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if intents := h.Bot.Intents(); intents&discordgo.IntentMessageContent != 0 {
+		t.Errorf("message content is required without commands: %d", intents)
+	}
+
+	h.Bot.GENERAL("ping", func(c discordant.Context) error { return c.Success() })
+
+	if intents := h.Bot.Intents(); intents != discordant.IntentsCommands {
+		t.Errorf("intents with commands %d, want %d", intents, discordant.IntentsCommands)
+	}
+
+	h.Bot.AddHandler(func(*discordgo.Session, *discordgo.MessageReactionAdd) {})
+	h.Bot.AddHandler(func(*discordgo.Session, *discordgo.GuildMemberAdd) {})
+
+	expected := discordant.IntentsCommands | discordgo.IntentGuildMessageReactions |
+		discordgo.IntentDirectMessageReactions | discordgo.IntentGuildMembers
+	if intents := h.Bot.Intents(); intents != expected {
+		t.Errorf("intents with handlers %d, want %d", intents, expected)
+	}
+
+	h = discordanttest.New(t, nil, discordant.AddIntents(discordgo.IntentGuildVoiceStates))
+
+	if intents := h.Bot.Intents(); intents&discordgo.IntentGuildVoiceStates == 0 {
+		t.Errorf("intents with AddIntents %d miss voice states", intents)
+	}
+
+	h = discordanttest.New(t, nil, discordant.SetIntents(discordgo.IntentGuilds))
+
+	if intents := h.Bot.Intents(); intents != discordgo.IntentGuilds {
+		t.Errorf("intents with SetIntents %d, want %d", intents, discordgo.IntentGuilds)
+	}
+}
+
+func TestRunIntents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"url":"ws://127.0.0.1:1"}`)
+	}))
+	defer srv.Close()
+
+	saved := discordgo.EndpointGateway
+	discordgo.EndpointGateway = srv.URL
+
+	defer func() { discordgo.EndpointGateway = saved }()
+
+	bot, err := discordant.New(&discordant.Config{Token: "secret", Prefix: "!"}, discordant.SetID(discordanttest.BotID))
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	bot.GENERAL("ping", func(c discordant.Context) error { return c.Success() })
+
+	if err := bot.Run(); err == nil {
+		t.Fatal("expected gateway connection error")
+	}
+
+	if intents := bot.Session().Identify.Intents; intents != discordant.IntentsCommands {
+		t.Errorf("opened with intents %d, want %d", intents, discordant.IntentsCommands)
+	}
+}
@@ -0,0 +1,284 @@
+// Package cron parses cron expressions and computes their activation times.
+//
+// Supported are standard five fields expressions `minute hour day-of-month
+// month day-of-week` with `*`, ranges `1-5`, lists `1,3`, steps `*/15` and
+// month and weekday names, and descriptors `@yearly`, `@annually`,
+// `@monthly`, `@weekly`, `@daily`, `@midnight`, `@hourly` and
+// `@every <duration>`.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSyntax is returned when expression can't be parsed.
+var ErrSyntax = errors.New("cron: syntax error")
+
+// searchLimit limits search of the next activation time for expressions that
+// never match such as `0 0 30 2 *`.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule computes activation times.
+type Schedule interface {
+	// Next returns the next activation time after t or zero time if there
+	// is no activation.
+	Next(t time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	weekdayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes  = bounds{0, 59, nil}
+	hours    = bounds{0, 23, nil}
+	days     = bounds{1, 31, nil}
+	months   = bounds{1, 12, monthNames}
+	weekdays = bounds{0, 7, weekdayNames}
+)
+
+// Parse parses cron expression or descriptor.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrSyntax, err)
+		}
+
+		if interval <= 0 {
+			return nil, fmt.Errorf("%w: interval must be positive: %s", ErrSyntax, rest)
+		}
+
+		return Every(interval), nil
+	}
+
+	if expr, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d: %q", ErrSyntax, len(fields), spec)
+	}
+
+	var (
+		s   expression
+		err error
+	)
+
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, err
+	}
+
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, err
+	}
+
+	if s.day, err = parseField(fields[2], days); err != nil {
+		return nil, err
+	}
+
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return nil, err
+	}
+
+	if s.weekday, err = parseField(fields[4], weekdays); err != nil {
+		return nil, err
+	}
+
+	// Sunday is both 0 and 7.
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1
+	}
+
+	s.anyDay = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	s.anyWeekday = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+
+	return &s, nil
+}
+
+// Every returns schedule that activates with the interval.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// expression is parsed cron expression. Fields are bit sets of allowed values.
+type expression struct {
+	minute, hour, day, month, weekday uint64
+	anyDay, anyWeekday                bool
+}
+
+// Next returns the next activation time after t in location of t.
+func (s *expression) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = after(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location()))
+
+			continue
+		}
+
+		if !s.matchDay(t) {
+			t = after(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()))
+
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = after(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location()))
+
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = nextMinute(t)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// after returns next if it is after t. Wall clock time in the gap when clocks
+// are set forward is normalized by time.Date to the time before the gap, so
+// then the next hour is returned to keep the search going.
+func after(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	return t.Truncate(time.Hour).Add(time.Hour)
+}
+
+// nextMinute returns the next minute of wall clock. When clocks are set back
+// for daylight saving time, the repeated hour is skipped, so that activation
+// times are not repeated. Activation times in the hour skipped when clocks
+// are set forward are lost.
+func nextMinute(t time.Time) time.Time {
+	next := t.Add(time.Minute)
+
+	if next.Minute() == 0 && next.Hour() == t.Hour() {
+		return after(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location()))
+	}
+
+	return next
+}
+
+// matchDay matches day of month and day of week. If both are restricted, the
+// day matches either of them like in Vixie cron.
+func (s *expression) matchDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// parseField parses comma separated list of ranges with optional steps.
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid step %q", ErrSyntax, part)
+			}
+		}
+
+		low, high := b.min, b.max
+
+		if rng != "*" {
+			lowStr, highStr, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if low, err = parseValue(lowStr, b); err != nil {
+				return 0, err
+			}
+
+			high = low
+
+			switch {
+			case isRange:
+				if high, err = parseValue(highStr, b); err != nil {
+					return 0, err
+				}
+			case hasStep:
+				high = b.max
+			}
+
+			if low > high {
+				return 0, fmt.Errorf("%w: invalid range %q", ErrSyntax, part)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+
+	return set, nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	if n, ok := b.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < b.min || n > b.max {
+		return 0, fmt.Errorf("%w: value %q out of range %d-%d", ErrSyntax, value, b.min, b.max)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,224 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+
+	result, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		from     string
+		expected []string
+	}{
+		{
+			name:     "every minute",
+			spec:     "* * * * *",
+			from:     "2025-01-01 10:00",
+			expected: []string{"2025-01-01 10:01", "2025-01-01 10:02"},
+		},
+		{
+			name:     "range",
+			spec:     "0 9-10 * * *",
+			from:     "2025-01-01 09:30",
+			expected: []string{"2025-01-01 10:00", "2025-01-02 09:00"},
+		},
+		{
+			name:     "step",
+			spec:     "*/20 * * * *",
+			from:     "2025-01-01 10:05",
+			expected: []string{"2025-01-01 10:20", "2025-01-01 10:40", "2025-01-01 11:00"},
+		},
+		{
+			name:     "range with step",
+			spec:     "0 8-18/5 * * *",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-01 08:00", "2025-01-01 13:00", "2025-01-01 18:00", "2025-01-02 08:00"},
+		},
+		{
+			name:     "value with step",
+			spec:     "50/5 * * * *",
+			from:     "2025-01-01 10:00",
+			expected: []string{"2025-01-01 10:50", "2025-01-01 10:55", "2025-01-01 11:50"},
+		},
+		{
+			name:     "list",
+			spec:     "0 0 1,15 * *",
+			from:     "2025-01-10 00:00",
+			expected: []string{"2025-01-15 00:00", "2025-02-01 00:00"},
+		},
+		{
+			name: "weekday names",
+			spec: "0 9 * * mon-fri",
+			// 2025-01-03 is Friday.
+			from:     "2025-01-03 10:00",
+			expected: []string{"2025-01-06 09:00", "2025-01-07 09:00"},
+		},
+		{
+			name:     "month names",
+			spec:     "0 0 1 jan,JUL *",
+			from:     "2025-02-01 00:00",
+			expected: []string{"2025-07-01 00:00", "2026-01-01 00:00"},
+		},
+		{
+			name:     "sunday as 7",
+			spec:     "0 12 * * 7",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-05 12:00", "2025-01-12 12:00"},
+		},
+		{
+			name:     "sunday as 0",
+			spec:     "0 12 * * 0",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-05 12:00"},
+		},
+		{
+			name: "day of month or weekday",
+			spec: "0 0 13 * fri",
+			// Both restricted fields match independently like in Vixie cron.
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-03 00:00", "2025-01-10 00:00", "2025-01-13 00:00", "2025-01-17 00:00"},
+		},
+		{
+			name:     "day of month with any weekday",
+			spec:     "0 0 13 * *",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-13 00:00", "2025-02-13 00:00"},
+		},
+		{
+			name:     "weekday with day step",
+			spec:     "0 0 */1 * mon",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-06 00:00", "2025-01-13 00:00"},
+		},
+		{
+			name:     "leap day",
+			spec:     "0 0 29 2 *",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2028-02-29 00:00"},
+		},
+		{
+			name:     "descriptor",
+			spec:     "@weekly",
+			from:     "2025-01-01 00:00",
+			expected: []string{"2025-01-05 00:00"},
+		},
+		{
+			name:     "never",
+			spec:     "0 0 30 2 *",
+			from:     "2025-01-01 00:00",
+			expected: []string{"0001-01-01 00:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse: %s", err)
+			}
+
+			next := date(t, time.UTC, tt.from)
+
+			for _, value := range tt.expected {
+				next = schedule.Next(next)
+
+				if expected := date(t, time.UTC, value); !next.Equal(expected) {
+					t.Fatalf("got %s, want %s", next, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %s", err)
+	}
+
+	schedule, err := Parse("30 1 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clocks are set back at 2:00 on 2025-11-02, so 1:30 happens twice.
+	first := schedule.Next(date(t, loc, "2025-11-02 00:00"))
+	if first.Hour() != 1 || first.Minute() != 30 || first.Day() != 2 {
+		t.Fatalf("first run %s", first)
+	}
+
+	if next := schedule.Next(first); next.Day() != 3 || next.Hour() != 1 || next.Minute() != 30 {
+		t.Errorf("run after %s is %s, want 1:30 next day", first, next)
+	}
+
+	hourly, err := Parse("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clocks are set forward at 2:00 on 2025-03-09, so 2:00 doesn't exist.
+	next := hourly.Next(date(t, loc, "2025-03-09 01:30"))
+	if next.Hour() != 3 || next.Sub(date(t, loc, "2025-03-09 01:30")) != 30*time.Minute {
+		t.Errorf("hourly run after 1:30 is %s, want 3:00", next)
+	}
+
+	skipped, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next := skipped.Next(date(t, loc, "2025-03-09 00:00")); next.Day() != 10 || next.Hour() != 2 {
+		t.Errorf("run of skipped hour is %s, want 2:30 next day", next)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	schedule, err := Parse("@every 90s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := date(t, time.UTC, "2025-01-01 00:00")
+
+	if next := schedule.Next(from); next.Sub(from) != 90*time.Second {
+		t.Errorf("got %s after %s", next, from)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"* * * foo *",
+		"@every 0s",
+		"@every -1m",
+		"@every soon",
+	} {
+		if _, err := Parse(spec); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q) error %v, want ErrSyntax", spec, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package events dispatches discordgo events to handlers without gateway connection.
+package events
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var sessionType = reflect.TypeOf((*discordgo.Session)(nil))
+
+// Dispatcher stores discordgo event handlers such as
+// func(*discordgo.Session, *discordgo.MessageCreate) and calls them synchronously
+// with nil session.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	next     int
+	handlers []entry
+}
+
+type entry struct {
+	id    int
+	value reflect.Value
+}
+
+// AddHandler adds event handler and returns function to remove it.
+// Nil handlers and handlers with invalid signature are ignored.
+func (d *Dispatcher) AddHandler(handler interface{}) func() {
+	value := reflect.ValueOf(handler)
+	if !value.IsValid() || (value.Kind() == reflect.Func && value.IsNil()) {
+		return func() {}
+	}
+
+	typ := value.Type()
+	if typ.Kind() != reflect.Func || typ.NumIn() != 2 || typ.In(0) != sessionType {
+		return func() {}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	id := d.next
+	d.next++
+	d.handlers = append(d.handlers, entry{id: id, value: value})
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		for i := range d.handlers {
+			if d.handlers[i].id == id {
+				d.handlers = append(d.handlers[:i], d.handlers[i+1:]...)
+
+				return
+			}
+		}
+	}
+}
+
+// Dispatch calls all handlers that accept the event type. Nil events are
+// ignored.
+func (d *Dispatcher) Dispatch(event interface{}) {
+	eventValue := reflect.ValueOf(event)
+	if !eventValue.IsValid() || (eventValue.Kind() == reflect.Pointer && eventValue.IsNil()) {
+		return
+	}
+
+	d.mu.RLock()
+
+	handlers := make([]reflect.Value, 0, len(d.handlers))
+
+	for _, h := range d.handlers {
+		if h.value.Type().In(1) == eventValue.Type() {
+			handlers = append(handlers, h.value)
+		}
+	}
+
+	d.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler.Call([]reflect.Value{reflect.Zero(sessionType), eventValue})
+	}
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDispatcher(t *testing.T) {
+	var (
+		d        Dispatcher
+		messages int
+		reacts   int
+	)
+
+	remove := d.AddHandler(func(_ *discordgo.Session, _ *discordgo.MessageCreate) { messages++ })
+	d.AddHandler(func(_ *discordgo.Session, _ *discordgo.MessageReactionAdd) { reacts++ })
+
+	d.Dispatch(&discordgo.MessageCreate{Message: &discordgo.Message{}})
+
+	if messages != 1 || reacts != 0 {
+		t.Fatalf("messages %d, reactions %d after message", messages, reacts)
+	}
+
+	remove()
+	d.Dispatch(&discordgo.MessageCreate{Message: &discordgo.Message{}})
+
+	if messages != 1 {
+		t.Errorf("removed handler is called")
+	}
+}
+
+func TestDispatcherNil(t *testing.T) {
+	var (
+		d     Dispatcher
+		calls int
+	)
+
+	var nilHandler func(*discordgo.Session, *discordgo.MessageCreate)
+
+	d.AddHandler(nil)
+	d.AddHandler(nilHandler)
+	d.AddHandler("not a handler")
+	d.AddHandler(func(*discordgo.MessageCreate) {})
+	d.AddHandler(func(_ *discordgo.Session, _ *discordgo.MessageCreate) { calls++ })
+
+	d.Dispatch(nil)
+	d.Dispatch((*discordgo.MessageCreate)(nil))
+
+	if calls != 0 {
+		t.Errorf("handler is called with nil event %d times", calls)
+	}
+
+	d.Dispatch(&discordgo.MessageCreate{})
+
+	if calls != 1 {
+		t.Errorf("handler is called %d times, want 1", calls)
+	}
+}
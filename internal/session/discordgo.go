@@ -1,39 +1,433 @@
 package session
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-// A Session represents a connection to the Discord API.
+// Session errors.
+var (
+	// ErrInvalidShard is returned when shard id is out of shard count.
+	ErrInvalidShard = errors.New("invalid shard id")
+
+	// ErrAlreadyOpen is returned when Open is called for opened session.
+	ErrAlreadyOpen = errors.New("session is already open")
+
+	// ErrClosed is returned by Open when the session is closed before all
+	// shards are opened.
+	ErrClosed = errors.New("session is closed")
+
+	// ErrSessionStartLimit is returned when Discord allows less session
+	// starts than shards to open.
+	ErrSessionStartLimit = errors.New("session start limit exceeded")
+)
+
+// identifyInterval is the interval between identifies of shards in the same
+// rate limit bucket.
+var identifyInterval = 5 * time.Second
+
+// Sharding describes gateway shards opened by the process.
+type Sharding struct {
+	// Count is the total number of shards. Zero means single connection
+	// without sharding unless Auto is set.
+	Count int
+
+	// Auto uses the shard count recommended by Discord.
+	Auto bool
+
+	// IDs are shards opened by the process. Empty means all shards.
+	IDs []int
+}
+
+// ShardStatus describes gateway connection of a single shard.
+type ShardStatus struct {
+	ID         int           `json:"id"`
+	Count      int           `json:"count"`
+	Connected  bool          `json:"connected"`
+	Ready      bool          `json:"ready"`
+	Latency    time.Duration `json:"latency"`
+	Reconnects int           `json:"reconnects"`
+	LastEvent  time.Time     `json:"last_event"`
+}
+
+// A Session represents a connection to the Discord API. The embedded session
+// is used for REST requests and as the first shard.
 type Session struct {
 	*discordgo.Session
 	owner bool
+
+	mu       sync.Mutex
+	token    string
+	handlers []*handler
+	shards   []*shard
+	opening  bool
+}
+
+type handler struct {
+	fn      interface{}
+	removes map[*discordgo.Session]func()
+}
+
+type shard struct {
+	session *discordgo.Session
+	untrack []func()
+
+	mu     sync.Mutex
+	status ShardStatus
 }
 
-// New creates a new Discord session and will automate some startup
-// tasks if given enough information to do so.
+// New creates a new Discord session without opening gateway connection.
+// REST requests can be made before Open.
 func New(token string) (*Session, error) {
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
 		return nil, fmt.Errorf("discord: create session: %w", err)
 	}
 
-	session.Identify.Intents = discordgo.MakeIntent(discordgo.IntentsAll)
+	return &Session{Session: session, owner: true, token: token}, nil
+}
+
+// Wrap wraps session that is created and opened outside.
+func Wrap(session *discordgo.Session) *Session {
+	return &Session{Session: session}
+}
+
+// AddHandler adds event handler to all shards, including shards that are
+// opened later. It returns function that removes the handler.
+func (s *Session) AddHandler(fn interface{}) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if err := session.Open(); err != nil {
-		return nil, fmt.Errorf("discord: open connection: %w", err)
+	h := handler{fn: fn, removes: map[*discordgo.Session]func(){s.Session: s.Session.AddHandler(fn)}}
+
+	for _, sh := range s.shards {
+		if sh.session != s.Session {
+			h.removes[sh.session] = sh.session.AddHandler(fn)
+		}
 	}
 
-	return &Session{session, true}, nil
+	s.handlers = append(s.handlers, &h)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, remove := range h.removes {
+			remove()
+		}
+
+		for i := range s.handlers {
+			if s.handlers[i] == &h {
+				s.handlers = append(s.handlers[:i], s.handlers[i+1:]...)
+
+				break
+			}
+		}
+	}
 }
 
-// Close closes a websocket and stops all listening/heartbeat goroutines.
-func (s *Session) Close() error {
+// Open opens gateway connections of the shards with the intents. Shards
+// identify no faster than Discord allows: one shard per rate limit bucket
+// every 5 seconds. The lock is not held while shards wait and connect, so
+// AddHandler and Shards don't block during startup. If a shard fails to open,
+// opened shards are closed. It does nothing if the session is not owned.
+func (s *Session) Open(intents discordgo.Intent, sharding Sharding) error {
+	if !s.owner {
+		return nil
+	}
+
+	s.mu.Lock()
+
+	if len(s.shards) != 0 || s.opening {
+		s.mu.Unlock()
+
+		return fmt.Errorf("discord: %w", ErrAlreadyOpen)
+	}
+
+	s.opening = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.opening = false
+		s.mu.Unlock()
+	}()
+
+	count, concurrency := sharding.Count, 1
+
+	if sharding.Auto || sharding.Count > 1 {
+		gateway, err := s.Session.GatewayBot()
+		if err != nil {
+			return fmt.Errorf("discord: get gateway session limits: %w", err)
+		}
+
+		if sharding.Auto {
+			count = gateway.Shards
+		}
+
+		if limit := gateway.SessionStartLimit; limit.MaxConcurrency > 0 {
+			concurrency = limit.MaxConcurrency
+		}
+
+		if limit := gateway.SessionStartLimit; limit.Total > 0 && limit.Remaining < shardsToOpen(sharding, count) {
+			return fmt.Errorf("discord: %w: %d remaining, reset after %s", ErrSessionStartLimit,
+				limit.Remaining, time.Duration(limit.ResetAfter)*time.Millisecond)
+		}
+	}
+
+	if count <= 0 {
+		count = 1
+	}
+
+	ids := sharding.IDs
+	if len(ids) == 0 {
+		ids = make([]int, count)
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+
+	for _, id := range ids {
+		if id < 0 || id >= count {
+			return fmt.Errorf("discord: %w: %d of %d", ErrInvalidShard, id, count)
+		}
+	}
+
+	limiter := newIdentifyLimiter(concurrency, identifyInterval)
+
+	for i, id := range ids {
+		if err := s.openShard(i, id, count, intents, limiter); err != nil {
+			s.mu.Lock()
+			s.closeShards()
+			s.mu.Unlock()
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openShard opens shard connection. The first shard uses the embedded
+// session. The shard is published under the lock before it connects, so
+// that handlers added meanwhile are added to it, and the lock is released
+// while the shard waits for identify and connects.
+func (s *Session) openShard(i, id, count int, intents discordgo.Intent, limiter *identifyLimiter) error {
+	ses, first := s.Session, i == 0
+
+	if !first {
+		var err error
+		if ses, err = discordgo.New("Bot " + s.token); err != nil {
+			return fmt.Errorf("discord: create shard %d session: %w", id, err)
+		}
+	}
+
+	s.mu.Lock()
+
+	if len(s.shards) != i {
+		// Opened shards are closed by Close.
+		s.mu.Unlock()
+
+		return fmt.Errorf("discord: open shard %d: %w", id, ErrClosed)
+	}
+
+	if !first {
+		for _, h := range s.handlers {
+			h.removes[ses] = ses.AddHandler(h.fn)
+		}
+	}
+
+	ses.Identify.Intents = intents
+	ses.ShardID = id
+	ses.ShardCount = count
+
+	sh := &shard{session: ses, status: ShardStatus{ID: id, Count: count}}
+	sh.track()
+
+	s.shards = append(s.shards, sh)
+
+	s.mu.Unlock()
+
+	limiter.wait(id)
+
+	if err := ses.Open(); err != nil {
+		return fmt.Errorf("discord: open shard %d connection: %w", id, err)
+	}
+
+	return nil
+}
+
+// closeShards closes shard connections and forgets the shards, so that the
+// session can be opened again. It must be called under the lock.
+func (s *Session) closeShards() []error {
+	var errs []error
+
+	for _, sh := range s.shards {
+		if err := sh.session.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close shard %d: %w", sh.status.ID, err))
+		}
+
+		for _, untrack := range sh.untrack {
+			untrack()
+		}
+
+		if sh.session == s.Session {
+			continue
+		}
+
+		for _, h := range s.handlers {
+			if remove, ok := h.removes[sh.session]; ok {
+				remove()
+				delete(h.removes, sh.session)
+			}
+		}
+	}
+
+	s.shards = nil
+
+	return errs
+}
+
+// shardsToOpen returns number of shards opened by the process.
+func shardsToOpen(sharding Sharding, count int) int {
+	if len(sharding.IDs) != 0 {
+		return len(sharding.IDs)
+	}
+
+	return max(count, 1)
+}
+
+// identifyLimiter paces shard identifies. Shard identifies in the bucket
+// `id % concurrency` and each bucket allows one identify per interval.
+type identifyLimiter struct {
+	concurrency int
+	interval    time.Duration
+	last        map[int]time.Time
+	now         func() time.Time
+	sleep       func(time.Duration)
+}
+
+func newIdentifyLimiter(concurrency int, interval time.Duration) *identifyLimiter {
+	return &identifyLimiter{
+		concurrency: max(concurrency, 1),
+		interval:    interval,
+		last:        make(map[int]time.Time),
+		now:         time.Now,
+		sleep:       time.Sleep,
+	}
+}
+
+// wait blocks until the shard can identify and records the identify.
+func (l *identifyLimiter) wait(id int) {
+	bucket := id % l.concurrency
+
+	if last, ok := l.last[bucket]; ok {
+		if delay := last.Add(l.interval).Sub(l.now()); delay > 0 {
+			l.sleep(delay)
+		}
+	}
+
+	l.last[bucket] = l.now()
+}
+
+// Intents returns intents the session identifies with. It returns zero for
+// owned session that is not opened yet, because its intents are set by Open.
+func (s *Session) Intents() discordgo.Intent {
 	if s.owner {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if len(s.shards) == 0 {
+			return 0
+		}
+	}
+
+	return s.Identify.Intents
+}
+
+// Shards returns status of opened shards.
+func (s *Session) Shards() []ShardStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.shards) == 0 && !s.owner && s.Session.DataReady {
+		return []ShardStatus{{
+			ID:        s.Session.ShardID,
+			Count:     s.Session.ShardCount,
+			Connected: true,
+			Ready:     true,
+			Latency:   s.Session.HeartbeatLatency(),
+		}}
+	}
+
+	statuses := make([]ShardStatus, 0, len(s.shards))
+
+	for _, sh := range s.shards {
+		statuses = append(statuses, sh.snapshot())
+	}
+
+	return statuses
+}
+
+// Close closes websockets of all shards and stops all listening/heartbeat
+// goroutines. The session can be opened again.
+func (s *Session) Close() error {
+	if !s.owner {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.shards) == 0 {
 		return s.Session.Close()
 	}
 
-	return nil
+	return errors.Join(s.closeShards()...)
+}
+
+// track updates shard status on connection events.
+func (sh *shard) track() {
+	sh.untrack = append(sh.untrack, sh.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Connect) {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+
+		if !sh.status.LastEvent.IsZero() {
+			sh.status.Reconnects++
+		}
+
+		sh.status.Connected = true
+		sh.status.LastEvent = time.Now()
+	}))
+
+	sh.untrack = append(sh.untrack, sh.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+
+		sh.status.Connected = false
+		sh.status.Ready = false
+		sh.status.LastEvent = time.Now()
+	}))
+
+	sh.untrack = append(sh.untrack, sh.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+
+		sh.status.Ready = true
+		sh.status.LastEvent = time.Now()
+	}))
+}
+
+func (sh *shard) snapshot() ShardStatus {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	status := sh.status
+	status.Latency = sh.session.HeartbeatLatency()
+
+	return status
 }
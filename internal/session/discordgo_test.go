@@ -0,0 +1,195 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// gateway serves gateway endpoints with unreachable websocket url.
+func gateway(t *testing.T, remaining int) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gateway":
+			fmt.Fprint(w, `{"url":"ws://127.0.0.1:1"}`)
+		case "/gateway/bot":
+			fmt.Fprintf(w, `{"url":"ws://127.0.0.1:1","shards":2,`+
+				`"session_start_limit":{"total":1000,"remaining":%d,"reset_after":1000,"max_concurrency":1}}`, remaining)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	saved, savedBot := discordgo.EndpointGateway, discordgo.EndpointGatewayBot
+	discordgo.EndpointGateway, discordgo.EndpointGatewayBot = srv.URL+"/gateway", srv.URL+"/gateway/bot"
+
+	t.Cleanup(func() {
+		discordgo.EndpointGateway, discordgo.EndpointGatewayBot = saved, savedBot
+		srv.Close()
+	})
+}
+
+func TestOpenClosesShardsOnError(t *testing.T) {
+	gateway(t, 1000)
+
+	s, err := New("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.AddHandler(func(*discordgo.Session, *discordgo.MessageCreate) {})
+
+	for i := 0; i < 2; i++ {
+		err := s.Open(discordgo.IntentsGuildMessages, Sharding{Auto: true})
+		if err == nil {
+			t.Fatal("expected open error")
+		}
+
+		if errors.Is(err, ErrAlreadyOpen) {
+			t.Fatalf("failed open is not retried: %s", err)
+		}
+
+		if len(s.shards) != 0 {
+			t.Errorf("%d shards are left after failed open", len(s.shards))
+		}
+
+		if n := len(s.handlers[0].removes); n != 1 {
+			t.Errorf("handler is left on %d sessions", n)
+		}
+	}
+}
+
+func TestOpenTwice(t *testing.T) {
+	s, err := New("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.shards = []*shard{{session: s.Session}}
+
+	if err := s.Open(discordgo.IntentsGuildMessages, Sharding{}); !errors.Is(err, ErrAlreadyOpen) {
+		t.Errorf("expected ErrAlreadyOpen, got %v", err)
+	}
+}
+
+func TestOpenSessionStartLimit(t *testing.T) {
+	gateway(t, 1)
+
+	s, err := New("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Open(discordgo.IntentsGuildMessages, Sharding{Count: 2}); !errors.Is(err, ErrSessionStartLimit) {
+		t.Errorf("expected ErrSessionStartLimit, got %v", err)
+	}
+}
+
+func TestIdentifyLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	var slept []time.Duration
+
+	l := newIdentifyLimiter(2, 5*time.Second)
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	for id := 0; id < 6; id++ {
+		l.wait(id)
+
+		now = now.Add(time.Second)
+	}
+
+	// Identifies are 1 second apart. Shards 2 and 4 wait for bucket 0 and the
+	// wait of shard 2 is enough for shards 3 and 5 in bucket 1.
+	want := []time.Duration{3 * time.Second, 3 * time.Second}
+	if fmt.Sprint(slept) != fmt.Sprint(want) {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+}
+
+func TestIntents(t *testing.T) {
+	s, err := New("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if intents := s.Intents(); intents != 0 {
+		t.Errorf("not opened session reports intents %d", intents)
+	}
+
+	wrapped := Wrap(s.Session)
+	wrapped.Identify.Intents = discordgo.IntentsGuildMessages
+
+	if intents := wrapped.Intents(); intents != discordgo.IntentsGuildMessages {
+		t.Errorf("wrapped session intents %d, want %d", intents, discordgo.IntentsGuildMessages)
+	}
+}
+
+func TestOpenReleasesLock(t *testing.T) {
+	requested, released := make(chan struct{}), make(chan struct{})
+	release := sync.OnceFunc(func() { close(released) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gateway/bot" {
+			close(requested)
+			<-released
+		}
+
+		fmt.Fprint(w, `{"url":"ws://127.0.0.1:1","shards":2}`)
+	}))
+	defer srv.Close()
+	defer release()
+
+	saved, savedBot := discordgo.EndpointGateway, discordgo.EndpointGatewayBot
+	discordgo.EndpointGateway, discordgo.EndpointGatewayBot = srv.URL+"/gateway", srv.URL+"/gateway/bot"
+
+	defer func() { discordgo.EndpointGateway, discordgo.EndpointGatewayBot = saved, savedBot }()
+
+	s, err := New("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened := make(chan error)
+
+	go func() { opened <- s.Open(discordgo.IntentsGuildMessages, Sharding{Count: 2}) }()
+
+	<-requested
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		s.Shards()
+		s.AddHandler(func(*discordgo.Session, *discordgo.MessageCreate) {})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Shards and AddHandler are blocked by Open")
+	}
+
+	if err := s.Open(discordgo.IntentsGuildMessages, Sharding{}); !errors.Is(err, ErrAlreadyOpen) {
+		t.Errorf("concurrent Open: %v, want ErrAlreadyOpen", err)
+	}
+
+	release()
+
+	if err := <-opened; err == nil {
+		t.Error("expected open error")
+	}
+}
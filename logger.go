@@ -1,8 +1,12 @@
 package discordant
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Logger is implemented by any logging system that is used for standard logs.
@@ -23,63 +27,240 @@ type Logger interface {
 	Errorln(args ...interface{})
 }
 
+// FieldLogger is implemented by loggers that support structured fields.
+type FieldLogger interface {
+	Logger
+
+	// WithFields returns logger that adds fields to every log line.
+	WithFields(fields Fields) Logger
+}
+
+// LevelLogger is implemented by loggers that skip lines below a level.
+type LevelLogger interface {
+	Logger
+
+	// Enabled reports whether lines of the level are written.
+	Enabled(level Level) bool
+}
+
+// Fields are structured log fields.
+type Fields map[string]interface{}
+
+// Log field names attached to every log line emitted while handling a command.
+// FieldBot is attached to logs of bots hosted by Manager, FieldJob to logs of
+// scheduled jobs.
+const (
+	FieldCommand   = "command"
+	FieldUser      = "user"
+	FieldGuild     = "guild"
+	FieldChannel   = "channel"
+	FieldRequestID = "request_id"
+	FieldBot       = "bot"
+	FieldJob       = "job"
+)
+
+// Level is a logging level.
+type Level int
+
+// Logging levels.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// String returns level name.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarning:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// ParseLevel parses level name such as "debug" or "warning".
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warning", "warn":
+		return LevelWarning, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("%w: %s", ErrInvalidLogLevel, name)
+	}
+}
+
+// WithFields returns logger that adds fields to every log line. If logger
+// doesn't implement FieldLogger, fields are appended to the message.
+func WithFields(logger Logger, fields Fields) Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+
+	if fl, ok := logger.(FieldLogger); ok {
+		return fl.WithFields(fields)
+	}
+
+	suffix := " " + formatFields(fields)
+
+	return &fieldsLog{Logger: logger, suffix: suffix, suffixf: strings.ReplaceAll(suffix, "%", "%%")}
+}
+
 type defaultLog struct {
 	*log.Logger
+	level  Level
+	fields Fields
+	suffix string
 }
 
-// NewDefaultLog creates and returns default logger to stderr.
+// NewDefaultLog creates and returns default logger to stderr with info level.
 func NewDefaultLog() Logger {
-	return &defaultLog{Logger: log.New(os.Stderr, "discordant ", log.LstdFlags)}
+	return NewLevelLog(LevelInfo)
+}
+
+// NewLevelLog creates and returns default logger to stderr that skips lines
+// below the level.
+func NewLevelLog(level Level) Logger {
+	return &defaultLog{Logger: log.New(os.Stderr, "discordant ", log.LstdFlags), level: level}
+}
+
+func (l *defaultLog) WithFields(fields Fields) Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return &defaultLog{Logger: l.Logger, level: l.level, fields: merged, suffix: " " + formatFields(merged)}
+}
+
+func (l *defaultLog) Enabled(level Level) bool {
+	return level >= l.level
 }
 
 func (l *defaultLog) Debugf(f string, v ...interface{}) {
-	l.printf("DEBUG: "+f, v...)
+	l.printf(LevelDebug, fmt.Sprintf(f, v...))
 }
 
 func (l *defaultLog) Debug(args ...interface{}) {
-	l.printf("DEBUG: %v", args...)
+	l.printf(LevelDebug, fmt.Sprint(args...))
 }
 
 func (l *defaultLog) Debugln(args ...interface{}) {
-	l.printf("DEBUG: %v\n", args...)
+	l.printf(LevelDebug, fmt.Sprintln(args...))
 }
 
 func (l *defaultLog) Infof(f string, v ...interface{}) {
-	l.printf("INFO: "+f, v...)
+	l.printf(LevelInfo, fmt.Sprintf(f, v...))
 }
 
 func (l *defaultLog) Info(args ...interface{}) {
-	l.printf("INFO: %v", args...)
+	l.printf(LevelInfo, fmt.Sprint(args...))
 }
 
 func (l *defaultLog) Infoln(args ...interface{}) {
-	l.printf("INFO: %v\n", args...)
+	l.printf(LevelInfo, fmt.Sprintln(args...))
 }
 
 func (l *defaultLog) Warningf(f string, v ...interface{}) {
-	l.printf("WARNING: "+f, v...)
+	l.printf(LevelWarning, fmt.Sprintf(f, v...))
 }
 
 func (l *defaultLog) Warning(args ...interface{}) {
-	l.printf("WARNING: %v", args...)
+	l.printf(LevelWarning, fmt.Sprint(args...))
 }
 
 func (l *defaultLog) Warningln(args ...interface{}) {
-	l.printf("WARNING: %v\n", args...)
+	l.printf(LevelWarning, fmt.Sprintln(args...))
 }
 
 func (l *defaultLog) Errorf(f string, v ...interface{}) {
-	l.printf("ERROR: "+f, v...)
+	l.printf(LevelError, fmt.Sprintf(f, v...))
 }
 
 func (l *defaultLog) Error(args ...interface{}) {
-	l.printf("ERROR: %v", args...)
+	l.printf(LevelError, fmt.Sprint(args...))
 }
 
 func (l *defaultLog) Errorln(args ...interface{}) {
-	l.printf("ERROR: %v\n", args...)
+	l.printf(LevelError, fmt.Sprintln(args...))
+}
+
+func (l *defaultLog) printf(level Level, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	l.Logger.Printf("%s: %s%s", level, strings.TrimSuffix(msg, "\n"), l.suffix)
+}
+
+// fieldsLog adds fields to messages of the logger that doesn't support fields.
+type fieldsLog struct {
+	Logger
+	suffix  string
+	suffixf string
+}
+
+func (l *fieldsLog) Enabled(level Level) bool            { return enabled(l.Logger, level) }
+func (l *fieldsLog) Debugf(f string, v ...interface{})   { l.Logger.Debugf(f+l.suffixf, v...) }
+func (l *fieldsLog) Infof(f string, v ...interface{})    { l.Logger.Infof(f+l.suffixf, v...) }
+func (l *fieldsLog) Warningf(f string, v ...interface{}) { l.Logger.Warningf(f+l.suffixf, v...) }
+func (l *fieldsLog) Errorf(f string, v ...interface{})   { l.Logger.Errorf(f+l.suffixf, v...) }
+func (l *fieldsLog) Debug(args ...interface{})           { l.Logger.Debug(fmt.Sprint(args...) + l.suffix) }
+func (l *fieldsLog) Info(args ...interface{})            { l.Logger.Info(fmt.Sprint(args...) + l.suffix) }
+func (l *fieldsLog) Warning(args ...interface{})         { l.Logger.Warning(fmt.Sprint(args...) + l.suffix) }
+func (l *fieldsLog) Error(args ...interface{})           { l.Logger.Error(fmt.Sprint(args...) + l.suffix) }
+func (l *fieldsLog) Debugln(args ...interface{})         { l.Logger.Debugln(sprintln(args...) + l.suffix) }
+func (l *fieldsLog) Infoln(args ...interface{})          { l.Logger.Infoln(sprintln(args...) + l.suffix) }
+func (l *fieldsLog) Warningln(args ...interface{})       { l.Logger.Warningln(sprintln(args...) + l.suffix) }
+func (l *fieldsLog) Errorln(args ...interface{})         { l.Logger.Errorln(sprintln(args...) + l.suffix) }
+
+// enabled reports whether logger writes lines of the level. Loggers that don't
+// implement LevelLogger are considered to write all levels.
+func enabled(logger Logger, level Level) bool {
+	if ll, ok := logger.(LevelLogger); ok {
+		return ll.Enabled(level)
+	}
+
+	return true
 }
 
-func (l *defaultLog) printf(f string, v ...interface{}) {
-	l.Logger.Printf(f, v...)
+// formatFields formats fields as sorted key=value pairs.
+func formatFields(fields Fields) string {
+	keys := make([]string, 0, len(fields))
+
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if strings.ContainsAny(value, " \t\n\"") {
+			value = strconv.Quote(value)
+		}
+
+		pairs = append(pairs, key+"="+value)
+	}
+
+	return strings.Join(pairs, " ")
 }
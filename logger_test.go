@@ -0,0 +1,178 @@
+package discordant_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/outdead/discordant"
+)
+
+// lineLogger records lines and doesn't support fields and levels.
+type lineLogger struct {
+	lines []string
+}
+
+func (l *lineLogger) add(msg string) { l.lines = append(l.lines, strings.TrimSuffix(msg, "\n")) }
+
+func (l *lineLogger) Debugf(f string, v ...interface{})   { l.add(fmt.Sprintf(f, v...)) }
+func (l *lineLogger) Infof(f string, v ...interface{})    { l.add(fmt.Sprintf(f, v...)) }
+func (l *lineLogger) Warningf(f string, v ...interface{}) { l.add(fmt.Sprintf(f, v...)) }
+func (l *lineLogger) Errorf(f string, v ...interface{})   { l.add(fmt.Sprintf(f, v...)) }
+func (l *lineLogger) Debug(args ...interface{})           { l.add(fmt.Sprint(args...)) }
+func (l *lineLogger) Info(args ...interface{})            { l.add(fmt.Sprint(args...)) }
+func (l *lineLogger) Warning(args ...interface{})         { l.add(fmt.Sprint(args...)) }
+func (l *lineLogger) Error(args ...interface{})           { l.add(fmt.Sprint(args...)) }
+func (l *lineLogger) Debugln(args ...interface{})         { l.add(fmt.Sprintln(args...)) }
+func (l *lineLogger) Infoln(args ...interface{})          { l.add(fmt.Sprintln(args...)) }
+func (l *lineLogger) Warningln(args ...interface{})       { l.add(fmt.Sprintln(args...)) }
+func (l *lineLogger) Errorln(args ...interface{})         { l.add(fmt.Sprintln(args...)) }
+
+// newLevelLog creates NewLevelLog logger that writes to the buffer.
+func newLevelLog(t *testing.T, level discordant.Level) (discordant.Logger, *bytes.Buffer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	logger := discordant.NewLevelLog(level)
+
+	output, ok := logger.(interface{ SetOutput(w io.Writer) })
+	if !ok {
+		t.Fatal("logger output can't be set")
+	}
+
+	output.SetOutput(&buf)
+
+	return logger, &buf
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected discordant.Level
+		err      error
+	}{
+		{name: "debug", expected: discordant.LevelDebug},
+		{name: "", expected: discordant.LevelInfo},
+		{name: "INFO", expected: discordant.LevelInfo},
+		{name: "warn", expected: discordant.LevelWarning},
+		{name: "warning", expected: discordant.LevelWarning},
+		{name: "Error", expected: discordant.LevelError},
+		{name: "trace", expected: discordant.LevelInfo, err: discordant.ErrInvalidLogLevel},
+	}
+
+	for _, tt := range tests {
+		level, err := discordant.ParseLevel(tt.name)
+		if level != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("ParseLevel(%q) = %s, %v, want %s, %v", tt.name, level, err, tt.expected, tt.err)
+		}
+	}
+}
+
+func TestLevelLog(t *testing.T) {
+	logger, buf := newLevelLog(t, discordant.LevelWarning)
+
+	logger.Info("skipped")
+	logger.Warningln("disk", 90, "%")
+	discordant.WithFields(logger, discordant.Fields{"user": "john doe", "id": 1}).Errorf("failed: %s", "io")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log lines:\n%s", buf.String())
+	}
+
+	if !strings.HasSuffix(lines[0], "WARNING: disk 90 %") {
+		t.Errorf("warning line %q", lines[0])
+	}
+
+	if !strings.HasSuffix(lines[1], `ERROR: failed: io id=1 user="john doe"`) {
+		t.Errorf("error line %q", lines[1])
+	}
+
+	ll, ok := logger.(discordant.LevelLogger)
+	if !ok || ll.Enabled(discordant.LevelInfo) || !ll.Enabled(discordant.LevelError) {
+		t.Errorf("level log doesn't report warning level")
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	var logger lineLogger
+
+	if discordant.WithFields(&logger, nil) != discordant.Logger(&logger) {
+		t.Errorf("logger is wrapped without fields")
+	}
+
+	fields := discordant.WithFields(&logger, discordant.Fields{"rate": "5%", "job": "backup"})
+
+	fields.Infof("done in %ds", 3)
+	fields.Error("a", "b")
+	fields.Warningln("a", "b", 1)
+
+	expected := []string{
+		`done in 3s job=backup rate=5%`,
+		`ab job=backup rate=5%`,
+		`a b 1 job=backup rate=5%`,
+	}
+
+	if strings.Join(logger.lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("lines:\n%s\nwant:\n%s", strings.Join(logger.lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	})
+
+	logger := discordant.NewSlogLogger(slog.New(handler))
+
+	logger.Debug("skipped")
+	discordant.WithFields(logger, discordant.Fields{"job": "backup"}).Warningln("disk", 90)
+
+	if expected := "level=WARN msg=\"disk 90\" job=backup\n"; buf.String() != expected {
+		t.Errorf("got %q, want %q", buf.String(), expected)
+	}
+
+	if ll, ok := logger.(discordant.LevelLogger); !ok || ll.Enabled(discordant.LevelDebug) {
+		t.Errorf("slog logger doesn't report handler level")
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	logger, buf := newLevelLog(t, discordant.LevelInfo)
+	handler := discordant.NewSlogHandler(logger)
+
+	if handler.Enabled(context.Background(), slog.LevelDebug) || !handler.Enabled(context.Background(), slog.LevelWarn) {
+		t.Errorf("handler doesn't respect logger level")
+	}
+
+	log := slog.New(handler).With("bot", "main").WithGroup("req")
+
+	log.Debug("skipped")
+	log.Warn("slow", "ms", 1500, slog.Group("user", "id", 42))
+
+	expected := "WARNING: slow bot=main req.ms=1500 req.user.id=42\n"
+	if !strings.HasSuffix(buf.String(), expected) || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("got %q, want suffix %q", buf.String(), expected)
+	}
+
+	var lines lineLogger
+
+	if !discordant.NewSlogHandler(&lines).Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("handler over logger without levels skips debug")
+	}
+}
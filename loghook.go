@@ -0,0 +1,362 @@
+package discordant
+
+import (
+	ctx "context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Log hook defaults.
+const (
+	DefaultLogHookLevel         = LevelWarning
+	DefaultLogHookFlushInterval = 5 * time.Second
+	DefaultLogHookRateLimit     = 10
+	DefaultLogHookRatePeriod    = time.Minute
+	DefaultLogHookBufferSize    = 100
+)
+
+// discordMaxEmbedDescriptionLen is the Discord limit of embed description.
+const discordMaxEmbedDescriptionLen = 4096
+
+// LogHookOption can be used to customize LogHook.
+type LogHookOption func(h *logHookCore)
+
+// LogHookLevel sets the minimum level of logs posted to the channel.
+func LogHookLevel(level Level) LogHookOption {
+	return func(h *logHookCore) {
+		h.level = level
+	}
+}
+
+// LogHookFlushInterval sets the interval of posting collected logs.
+func LogHookFlushInterval(interval time.Duration) LogHookOption {
+	return func(h *logHookCore) {
+		h.flushInterval = interval
+	}
+}
+
+// LogHookRateLimit limits the number of messages posted to the channel per
+// period. Logs collected above the limit are posted later.
+func LogHookRateLimit(messages int, period time.Duration) LogHookOption {
+	return func(h *logHookCore) {
+		h.rateLimit = messages
+		h.ratePeriod = period
+	}
+}
+
+// LogHookBufferSize sets the maximum number of distinct log lines waiting for
+// posting. Lines above the limit are dropped and counted.
+func LogHookBufferSize(size int) LogHookOption {
+	return func(h *logHookCore) {
+		h.bufferSize = size
+	}
+}
+
+// LogHook is Logger that passes logs to the next Logger and posts warning and
+// error logs to Discord channel. Logs are batched into embeds colored with
+// ColorRed if the batch has errors or ColorYellow otherwise. Repeated lines
+// are posted once with the count. Fields are passed to the next Logger but
+// are not posted, so that lines of different requests are deduplicated.
+//
+// Discordant logger is set before the bot is created, so the hook collects
+// logs until Bind is called.
+type LogHook struct {
+	core *logHookCore
+	next Logger
+}
+
+var _ FieldLogger = (*LogHook)(nil)
+
+type logHookCore struct {
+	level         Level
+	flushInterval time.Duration
+	rateLimit     int
+	ratePeriod    time.Duration
+	bufferSize    int
+	next          Logger
+
+	mu         sync.Mutex
+	discordant *Discordant
+	channelID  string
+	entries    []*logHookEntry
+	index      map[string]*logHookEntry
+	dropped    int
+	sent       []time.Time
+
+	done chan struct{}
+	stop sync.Once
+	wg   sync.WaitGroup
+}
+
+type logHookEntry struct {
+	level Level
+	text  string
+	count int
+}
+
+// NewLogHook creates LogHook that passes logs to the next Logger and starts
+// periodic posting. The next Logger may be nil. The caller should call
+// Shutdown when finished.
+func NewLogHook(next Logger, options ...LogHookOption) *LogHook {
+	core := logHookCore{
+		level:         DefaultLogHookLevel,
+		flushInterval: DefaultLogHookFlushInterval,
+		rateLimit:     DefaultLogHookRateLimit,
+		ratePeriod:    DefaultLogHookRatePeriod,
+		bufferSize:    DefaultLogHookBufferSize,
+		next:          next,
+		index:         make(map[string]*logHookEntry),
+		done:          make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(&core)
+	}
+
+	core.wg.Add(1)
+
+	go core.run()
+
+	return &LogHook{core: &core, next: next}
+}
+
+// Bind sets the bot and the channel resolved by name from its config where
+// logs are posted.
+func (h *LogHook) Bind(d *Discordant, channelName string) error {
+	if channelName == "" {
+		return fmt.Errorf("discordant log hook: %w", ErrEmptyChannelID)
+	}
+
+	channelID, err := d.channelID(channelName)
+	if err != nil {
+		return fmt.Errorf("discordant log hook: %w", err)
+	}
+
+	h.core.mu.Lock()
+	h.core.discordant = d
+	h.core.channelID = channelID
+	h.core.mu.Unlock()
+
+	return nil
+}
+
+// Handler returns slog.Handler that writes to the hook.
+func (h *LogHook) Handler() slog.Handler {
+	return NewSlogHandler(h)
+}
+
+// Flush posts collected logs to the channel. It respects the rate limit.
+func (h *LogHook) Flush(c ctx.Context) error {
+	return h.core.flush(c, false)
+}
+
+// Shutdown stops periodic posting and posts remaining logs ignoring the rate
+// limit. It is safe to call Shutdown more than once.
+func (h *LogHook) Shutdown(c ctx.Context) error {
+	h.core.stop.Do(func() { close(h.core.done) })
+	h.core.wg.Wait()
+
+	return h.core.flush(c, true)
+}
+
+// WithFields returns logger that passes fields to the next Logger.
+func (h *LogHook) WithFields(fields Fields) Logger {
+	if h.next == nil {
+		return h
+	}
+
+	return &LogHook{core: h.core, next: WithFields(h.next, fields)}
+}
+
+func (h *LogHook) Debugf(f string, v ...interface{})   { h.log(LevelDebug, fmt.Sprintf(f, v...)) }
+func (h *LogHook) Infof(f string, v ...interface{})    { h.log(LevelInfo, fmt.Sprintf(f, v...)) }
+func (h *LogHook) Warningf(f string, v ...interface{}) { h.log(LevelWarning, fmt.Sprintf(f, v...)) }
+func (h *LogHook) Errorf(f string, v ...interface{})   { h.log(LevelError, fmt.Sprintf(f, v...)) }
+func (h *LogHook) Debug(args ...interface{})           { h.log(LevelDebug, fmt.Sprint(args...)) }
+func (h *LogHook) Info(args ...interface{})            { h.log(LevelInfo, fmt.Sprint(args...)) }
+func (h *LogHook) Warning(args ...interface{})         { h.log(LevelWarning, fmt.Sprint(args...)) }
+func (h *LogHook) Error(args ...interface{})           { h.log(LevelError, fmt.Sprint(args...)) }
+func (h *LogHook) Debugln(args ...interface{})         { h.log(LevelDebug, sprintln(args...)) }
+func (h *LogHook) Infoln(args ...interface{})          { h.log(LevelInfo, sprintln(args...)) }
+func (h *LogHook) Warningln(args ...interface{})       { h.log(LevelWarning, sprintln(args...)) }
+func (h *LogHook) Errorln(args ...interface{})         { h.log(LevelError, sprintln(args...)) }
+
+func (h *LogHook) log(level Level, msg string) {
+	if h.next != nil {
+		switch level {
+		case LevelDebug:
+			h.next.Debug(msg)
+		case LevelInfo:
+			h.next.Info(msg)
+		case LevelWarning:
+			h.next.Warning(msg)
+		default:
+			h.next.Error(msg)
+		}
+	}
+
+	h.core.add(level, msg)
+}
+
+func (h *logHookCore) add(level Level, msg string) {
+	if level < h.level {
+		return
+	}
+
+	msg = strings.TrimSpace(msg)
+	key := level.String() + ":" + msg
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry, ok := h.index[key]; ok {
+		entry.count++
+
+		return
+	}
+
+	if len(h.entries) >= h.bufferSize {
+		h.dropped++
+
+		return
+	}
+
+	entry := logHookEntry{level: level, text: msg, count: 1}
+
+	h.entries = append(h.entries, &entry)
+	h.index[key] = &entry
+}
+
+func (h *logHookCore) run() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+		}
+
+		if err := h.flush(ctx.Background(), false); err != nil && h.next != nil {
+			// Log to the next logger only to avoid posting loop.
+			h.next.Errorf("discordant log hook: %s", err)
+		}
+	}
+}
+
+// flush posts collected logs. Embeds are posted until the entries are over
+// or the rate limit is reached.
+func (h *logHookCore) flush(c ctx.Context, force bool) error {
+	for {
+		h.mu.Lock()
+
+		if h.discordant == nil || (len(h.entries) == 0 && h.dropped == 0) || (!force && !h.allow()) {
+			h.mu.Unlock()
+
+			return nil
+		}
+
+		d, channelID := h.discordant, h.channelID
+		embed := h.embed()
+
+		h.mu.Unlock()
+
+		if _, err := d.sendEmbed(c, channelID, embed); err != nil {
+			return err
+		}
+	}
+}
+
+// allow checks the rate limit and records the message. It must be called
+// under the lock.
+func (h *logHookCore) allow() bool {
+	if h.rateLimit <= 0 {
+		return true
+	}
+
+	now := time.Now()
+
+	for len(h.sent) > 0 && now.Sub(h.sent[0]) >= h.ratePeriod {
+		h.sent = h.sent[1:]
+	}
+
+	if len(h.sent) >= h.rateLimit {
+		return false
+	}
+
+	h.sent = append(h.sent, now)
+
+	return true
+}
+
+// embed takes entries that fit in one embed. It must be called under the lock.
+func (h *logHookCore) embed() *discordgo.MessageEmbed {
+	var (
+		description strings.Builder
+		errs, warns int
+		taken, size int
+	)
+
+	for _, entry := range h.entries {
+		// Log text may come from user input, so it can't close the fence.
+		line := entry.level.String() + ": " + escapeCodeBlock(entry.text)
+		if entry.count > 1 {
+			line = fmt.Sprintf("(x%d) %s", entry.count, line)
+		}
+
+		// Discord limits embed description by characters, not bytes.
+		runes := []rune("```" + line + "```")
+
+		if size+len(runes) > discordMaxEmbedDescriptionLen {
+			if taken != 0 {
+				break
+			}
+
+			// Single line is too long. Cut backtick loses its escape, so it
+			// is trimmed too.
+			cut := strings.TrimRight(string(runes[:discordMaxEmbedDescriptionLen-3]), "`")
+			runes = append([]rune(cut), []rune("```")...)
+		}
+
+		description.WriteString(string(runes))
+		size += len(runes)
+
+		if entry.level >= LevelError {
+			errs += entry.count
+		} else {
+			warns += entry.count
+		}
+
+		delete(h.index, entry.level.String()+":"+entry.text)
+
+		taken++
+	}
+
+	h.entries = h.entries[taken:]
+
+	embed := discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%d errors, %d warnings", errs, warns),
+		Description: description.String(),
+		Color:       ColorYellow,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if errs > 0 {
+		embed.Color = ColorRed
+	}
+
+	if h.dropped > 0 {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d log lines dropped", h.dropped)}
+		h.dropped = 0
+	}
+
+	return &embed
+}
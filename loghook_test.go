@@ -0,0 +1,155 @@
+package discordant_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestLogHookLongMultibyteLine(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	hook := discordant.NewLogHook(nil)
+	if err := hook.Bind(h.Bot, discordant.ChannelGeneral); err != nil {
+		t.Fatal(err)
+	}
+
+	// Over 4096 bytes, but under 4096 characters.
+	hook.Error(strings.Repeat("ж", 2100))
+	// Over 4096 characters.
+	hook.Error(strings.Repeat("ж", 5000))
+
+	if err := hook.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := h.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	for _, msg := range messages {
+		description := msg.Embeds[0].Description
+		if n := utf8.RuneCountInString(description); n > 4096 {
+			t.Errorf("description has %d characters", n)
+		}
+
+		if !strings.HasPrefix(description, "```") || !strings.HasSuffix(description, "```") {
+			t.Errorf("description is not fenced: %.20q", description)
+		}
+	}
+
+	if n := utf8.RuneCountInString(messages[0].Embeds[0].Description); n != len("```error: ```")+2100 {
+		t.Errorf("short line is truncated to %d characters", n)
+	}
+}
+
+func newLogHook(t *testing.T, h *discordanttest.Harness, options ...discordant.LogHookOption) *discordant.LogHook {
+	t.Helper()
+
+	options = append([]discordant.LogHookOption{discordant.LogHookFlushInterval(time.Hour)}, options...)
+
+	hook := discordant.NewLogHook(nil, options...)
+	if err := hook.Bind(h.Bot, discordant.ChannelGeneral); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = hook.Shutdown(context.Background()) })
+
+	return hook
+}
+
+func TestLogHookDeduplicates(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	hook := newLogHook(t, h)
+
+	for range 3 {
+		hook.Error("disk is full")
+	}
+
+	hook.Warning("disk is almost full")
+	hook.Info("disk is fine")
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	h.AssertEmbed("3 errors, 1 warnings")
+
+	embed := h.LastMessage().Embeds[0]
+	if embed.Description != "```(x3) ERROR: disk is full``````WARNING: disk is almost full```" {
+		t.Errorf("description %q", embed.Description)
+	}
+
+	if embed.Color != discordant.ColorRed {
+		t.Errorf("color %x, want red", embed.Color)
+	}
+}
+
+func TestLogHookRateLimit(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	hook := newLogHook(t, h, discordant.LogHookRateLimit(1, time.Hour))
+
+	// Every line takes a whole embed.
+	for i := range 3 {
+		hook.Warning(fmt.Sprintf("%d %s", i, strings.Repeat("x", 3000)))
+	}
+
+	for range 2 {
+		if err := hook.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		h.AssertMessagesCount(1)
+	}
+
+	if err := hook.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	h.AssertMessagesCount(3)
+
+	if err := hook.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown: %s", err)
+	}
+}
+
+func TestLogHookBufferSize(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	hook := newLogHook(t, h, discordant.LogHookBufferSize(2))
+
+	for i := range 3 {
+		hook.Error(fmt.Sprintf("error %d", i))
+	}
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	embed := h.LastMessage().Embeds[0]
+	if embed.Footer == nil || embed.Footer.Text != "1 log lines dropped" {
+		t.Errorf("footer %+v", embed.Footer)
+	}
+}
+
+func TestLogHookEscapesCodeBlock(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	hook := newLogHook(t, h)
+
+	hook.Error("unknown command ```@everyone```")
+
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	description := h.LastMessage().Embeds[0].Description
+	if strings.Count(description, "```") != 2 {
+		t.Errorf("log text closes the code block: %q", description)
+	}
+}
@@ -0,0 +1,179 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Manager hosts several Discordant bots with different tokens and configs in
+// one process. Options passed to NewManager are applied to every bot, so the
+// bots can share logger, metrics, tracer and store.
+type Manager struct {
+	options []Option
+
+	mu     sync.RWMutex
+	bots   map[string]*Discordant
+	names  []string
+	closed map[string]bool
+}
+
+// NewManager creates Manager with options shared by all bots.
+func NewManager(options ...Option) *Manager {
+	return &Manager{
+		options: options,
+		bots:    make(map[string]*Discordant),
+		closed:  make(map[string]bool),
+	}
+}
+
+// Add creates bot with the config, shared options followed by the options
+// and registers it by name. Logs of the bot have FieldBot field, metrics
+// implementing BotMetrics are labeled with its name and store keys of the
+// bot are prefixed with its name, so bots can share one store.
+func (m *Manager) Add(name string, cfg *Config, options ...Option) (*Discordant, error) {
+	// Reserve the name, so the bot can be created outside the lock.
+	m.mu.Lock()
+
+	if _, ok := m.bots[name]; ok {
+		m.mu.Unlock()
+
+		return nil, fmt.Errorf("discordant manager: %w: %s", ErrDuplicateBot, name)
+	}
+
+	m.bots[name] = nil
+
+	m.mu.Unlock()
+
+	all := make([]Option, 0, len(m.options)+len(options)+1)
+	all = append(all, m.options...)
+	all = append(all, options...)
+	all = append(all, managedBot(name))
+
+	d, err := New(cfg, all...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		delete(m.bots, name)
+
+		return nil, fmt.Errorf("discordant manager: %s: %w", name, err)
+	}
+
+	m.bots[name] = d
+	m.names = append(m.names, name)
+
+	return d, nil
+}
+
+// managedBot scopes logger, metrics and store of the bot by its name. It
+// must be the last option, so it wraps values set by other options.
+func managedBot(name string) Option {
+	return func(d *Discordant) {
+		d.logger = WithFields(d.logger, Fields{FieldBot: name})
+		d.store = NewPrefixStore(d.store, name+"/")
+
+		if metrics, ok := d.metrics.(BotMetrics); ok {
+			d.metrics = metrics.WithBot(name)
+		}
+	}
+}
+
+// Bot returns bot by name.
+func (m *Manager) Bot(name string) (*Discordant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Bot that is being added is nil until New returns.
+	d, ok := m.bots[name]
+	if !ok || d == nil {
+		return nil, fmt.Errorf("discordant manager: %w: %s", ErrUnknownBot, name)
+	}
+
+	return d, nil
+}
+
+// Names returns names of the bots in order they were added.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string(nil), m.names...)
+}
+
+// Run runs all bots in order they were added. If a bot fails to run, bots
+// that are already running are shut down. Bots are run without the lock, so
+// slow gateway connections don't block Add and Bot.
+func (m *Manager) Run() error {
+	names, bots := m.snapshot()
+
+	for i, d := range bots {
+		if err := d.Run(); err != nil {
+			for j := i; j >= 0; j-- {
+				_ = m.close(names[j], bots[j])
+			}
+
+			return fmt.Errorf("discordant manager: run %s: %w", names[i], err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes all bots in reverse order. Bots that are closed by failed
+// Run are skipped. It returns joined errors of all bots that failed to close.
+func (m *Manager) Shutdown() error {
+	names, bots := m.snapshot()
+
+	var errs []error
+
+	for i := len(bots) - 1; i >= 0; i-- {
+		if err := m.close(names[i], bots[i]); err != nil {
+			errs = append(errs, fmt.Errorf("discordant manager: close %s: %w", names[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// snapshot returns names and bots in order they were added.
+func (m *Manager) snapshot() ([]string, []*Discordant) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bots := make([]*Discordant, len(m.names))
+	for i, name := range m.names {
+		bots[i] = m.bots[name]
+	}
+
+	return append([]string(nil), m.names...), bots
+}
+
+// close closes the bot once.
+func (m *Manager) close(name string, d *Discordant) error {
+	m.mu.Lock()
+
+	if m.closed[name] {
+		m.mu.Unlock()
+
+		return nil
+	}
+
+	m.closed[name] = true
+
+	m.mu.Unlock()
+
+	return d.Close()
+}
+
+// Notify sends message to the channel of the bot. Channel is resolved by
+// name from the bot config.
+func (m *Manager) Notify(bot, channelName, msg string, params ...string) error {
+	d, err := m.Bot(bot)
+	if err != nil {
+		return err
+	}
+
+	return d.Notify(channelName, msg, params...)
+}
@@ -0,0 +1,163 @@
+package discordant_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestManagerScopesBots(t *testing.T) {
+	metrics := discordant.NewPrometheusMetrics("test")
+	store := discordant.NewMemoryStore()
+	manager := discordant.NewManager(discordant.SetMetrics(metrics), discordant.SetStore(store))
+
+	sessions := make(map[string]*discordanttest.Session)
+
+	for _, name := range []string{"alpha", "beta"} {
+		sessions[name] = discordanttest.NewSession()
+
+		bot, err := manager.Add(name, discordanttest.NewConfig(), discordant.SetTransport(sessions[name]))
+		if err != nil {
+			t.Fatalf("Add %s: %s", name, err)
+		}
+
+		bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Success() })
+
+		if err := bot.Store().Set("key", []byte(name), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := manager.Run(); err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+
+	t.Cleanup(func() { _ = manager.Shutdown() })
+
+	sessions["alpha"].Dispatch(&discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "1",
+		ChannelID: discordanttest.GeneralChannelID,
+		GuildID:   discordanttest.GuildID,
+		Content:   "!ping",
+		Author:    &discordgo.User{ID: discordanttest.UserID},
+	}})
+
+	var buf strings.Builder
+
+	if err := metrics.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `test_commands_total{bot="alpha",command="ping",outcome="success"} 1`) {
+		t.Errorf("metrics have no bot label:\n%s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), `bot="beta",command="ping"`) {
+		t.Errorf("beta metrics are counted:\n%s", buf.String())
+	}
+
+	for _, name := range []string{"alpha", "beta"} {
+		value, err := store.Get(name + "/key")
+		if err != nil || string(value) != name {
+			t.Errorf("store key of %s: %q, %v", name, value, err)
+		}
+	}
+}
+
+func TestManagerAddDuplicate(t *testing.T) {
+	manager := discordant.NewManager()
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		added      int
+		duplicates int
+	)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := manager.Add("bot", discordanttest.NewConfig(),
+				discordant.SetTransport(discordanttest.NewSession()))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err == nil:
+				added++
+			case errors.Is(err, discordant.ErrDuplicateBot):
+				duplicates++
+			default:
+				t.Errorf("Add: %s", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if added != 1 || duplicates != 9 {
+		t.Errorf("added %d, duplicates %d", added, duplicates)
+	}
+
+	if names := manager.Names(); len(names) != 1 {
+		t.Errorf("names %v", names)
+	}
+}
+
+// reminderStore calls load on Reminders, which is called by Run.
+type reminderStore struct {
+	discordant.ReminderStore
+
+	load func() error
+}
+
+func (s reminderStore) Reminders() ([]discordant.Reminder, error) {
+	return nil, s.load()
+}
+
+func TestManagerRunFailure(t *testing.T) {
+	manager := discordant.NewManager()
+
+	add := func(name string, load func() error) {
+		t.Helper()
+
+		bot, err := manager.Add(name, discordanttest.NewConfig(), discordant.SetTransport(discordanttest.NewSession()))
+		if err != nil {
+			t.Fatalf("Add %s: %s", name, err)
+		}
+
+		bot.AddReminderCommands(reminderStore{load: load})
+	}
+
+	// Manager is not locked while bots run.
+	add("alpha", func() error {
+		_, err := manager.Add("gamma", discordanttest.NewConfig(), discordant.SetTransport(discordanttest.NewSession()))
+
+		return err
+	})
+
+	errLoad := errors.New("store is down")
+
+	add("beta", func() error { return errLoad })
+
+	if err := manager.Run(); !errors.Is(err, errLoad) {
+		t.Fatalf("Run: %v, want %v", err, errLoad)
+	}
+
+	if _, err := manager.Bot("gamma"); err != nil {
+		t.Errorf("bot added while running: %s", err)
+	}
+
+	if err := manager.Shutdown(); err != nil {
+		t.Errorf("Shutdown after failed Run: %s", err)
+	}
+}
@@ -0,0 +1,64 @@
+package discordant
+
+import "time"
+
+// Command outcomes.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeError    = "error"
+	OutcomeRejected = "rejected"
+	OutcomeDisabled = "disabled"
+	OutcomeDenied   = "denied"
+	OutcomeUnknown  = "unknown"
+)
+
+// Outbound message kinds.
+const (
+	MessageKindText     = "text"
+	MessageKindFile     = "file"
+	MessageKindEmbed    = "embed"
+	MessageKindReaction = "reaction"
+	MessageKindEdit     = "edit"
+	MessageKindResponse = "response"
+)
+
+// Metrics is implemented by any metrics backend that collects Discordant
+// statistics. PrometheusMetrics is the built-in implementation.
+type Metrics interface {
+	// CommandInvoked is called when command handling is finished with one of
+	// the outcomes. Duration is the handler latency.
+	CommandInvoked(command, outcome string, duration time.Duration)
+
+	// AccessDenied is called when command is not allowed in the channel.
+	AccessDenied(command string)
+
+	// UnknownCommand is called when message with prefix doesn't match any command.
+	UnknownCommand()
+
+	// MessageSent is called when message of the kind is sent to Discord.
+	MessageSent(kind string)
+
+	// MessageFailed is called when message of the kind can't be sent to Discord.
+	MessageFailed(kind string)
+
+	// MessageOverflow is called when Send attaches too long message as a file.
+	MessageOverflow()
+}
+
+// BotMetrics is implemented by Metrics that can tell bots hosted by Manager
+// apart. Manager passes Metrics returned by WithBot to each bot.
+type BotMetrics interface {
+	Metrics
+
+	// WithBot returns Metrics that labels values with bot name.
+	WithBot(name string) Metrics
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CommandInvoked(string, string, time.Duration) {}
+func (nopMetrics) AccessDenied(string)                          {}
+func (nopMetrics) UnknownCommand()                              {}
+func (nopMetrics) MessageSent(string)                           {}
+func (nopMetrics) MessageFailed(string)                         {}
+func (nopMetrics) MessageOverflow()                             {}
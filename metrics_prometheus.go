@@ -0,0 +1,283 @@
+package discordant
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are histogram buckets for handler latency in seconds.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// PrometheusMetrics is Metrics implementation that exposes collected values
+// in the Prometheus text format with Handler.
+type PrometheusMetrics struct {
+	*prometheusData
+
+	// bot is the value of bot label. Empty bot means no label.
+	bot string
+}
+
+// prometheusData is shared by PrometheusMetrics of all bots.
+type prometheusData struct {
+	namespace string
+	buckets   []float64
+
+	mu        sync.Mutex
+	commands  map[[3]string]uint64
+	durations map[[2]string]*histogram
+	denials   map[[2]string]uint64
+	unknown   map[string]uint64
+	sent      map[[2]string]uint64
+	failed    map[[2]string]uint64
+	overflows map[string]uint64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var _ BotMetrics = (*PrometheusMetrics)(nil)
+
+// NewPrometheusMetrics creates PrometheusMetrics with metrics names prefixed by
+// namespace. DefaultDurationBuckets are used if buckets are not set.
+func NewPrometheusMetrics(namespace string, buckets ...float64) *PrometheusMetrics {
+	if namespace == "" {
+		namespace = "discordant"
+	}
+
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusMetrics{prometheusData: &prometheusData{
+		namespace: namespace,
+		buckets:   buckets,
+		commands:  make(map[[3]string]uint64),
+		durations: make(map[[2]string]*histogram),
+		denials:   make(map[[2]string]uint64),
+		unknown:   make(map[string]uint64),
+		sent:      make(map[[2]string]uint64),
+		failed:    make(map[[2]string]uint64),
+		overflows: make(map[string]uint64),
+	}}
+}
+
+// WithBot returns PrometheusMetrics that shares values with m and labels
+// them with bot name. Handler of any of them writes values of all bots.
+func (m *PrometheusMetrics) WithBot(name string) Metrics {
+	return &PrometheusMetrics{prometheusData: m.prometheusData, bot: name}
+}
+
+// CommandInvoked increments commands counter and observes handler latency.
+func (m *PrometheusMetrics) CommandInvoked(command, outcome string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commands[[3]string{m.bot, command, outcome}]++
+
+	if outcome != OutcomeSuccess && outcome != OutcomeError {
+		return
+	}
+
+	key := [2]string{m.bot, command}
+
+	hist, ok := m.durations[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[key] = hist
+	}
+
+	seconds := duration.Seconds()
+
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			hist.counts[i]++
+		}
+	}
+
+	hist.sum += seconds
+	hist.count++
+}
+
+// AccessDenied increments access denials counter.
+func (m *PrometheusMetrics) AccessDenied(command string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.denials[[2]string{m.bot, command}]++
+}
+
+// UnknownCommand increments unknown commands counter.
+func (m *PrometheusMetrics) UnknownCommand() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.unknown[m.bot]++
+}
+
+// MessageSent increments sent messages counter.
+func (m *PrometheusMetrics) MessageSent(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent[[2]string{m.bot, kind}]++
+}
+
+// MessageFailed increments failed messages counter.
+func (m *PrometheusMetrics) MessageFailed(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failed[[2]string{m.bot, kind}]++
+}
+
+// MessageOverflow increments overflow-to-file counter.
+func (m *PrometheusMetrics) MessageOverflow() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.overflows[m.bot]++
+}
+
+// Handler returns http.Handler that serves metrics in the Prometheus text format.
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		_ = m.Write(w)
+	})
+}
+
+// Write writes metrics in the Prometheus text format. Values of bots hosted
+// by Manager have bot label.
+func (m *PrometheusMetrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf strings.Builder
+
+	name := m.namespace + "_commands_total"
+	writeHeader(&buf, name, "counter", "Number of handled commands by name and outcome.")
+
+	for _, key := range sortedKeys(m.commands) {
+		fmt.Fprintf(&buf, "%s{%s} %d\n",
+			name, labels(key[0], "command", key[1], "outcome", key[2]), m.commands[key])
+	}
+
+	name = m.namespace + "_command_duration_seconds"
+	writeHeader(&buf, name, "histogram", "Command handler latency in seconds.")
+
+	for _, key := range sortedKeys(m.durations) {
+		hist := m.durations[key]
+		series := labels(key[0], "command", key[1])
+
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, series, formatFloat(bound), hist.counts[i])
+		}
+
+		fmt.Fprintf(&buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, series, hist.count)
+		fmt.Fprintf(&buf, "%s_sum{%s} %s\n", name, series, formatFloat(hist.sum))
+		fmt.Fprintf(&buf, "%s_count{%s} %d\n", name, series, hist.count)
+	}
+
+	writeCounterVec(&buf, m.namespace+"_access_denied_total", "Number of commands denied in the channel.",
+		"command", m.denials)
+	writeCounter(&buf, m.namespace+"_unknown_commands_total",
+		"Number of messages with prefix that don't match any command.", m.unknown)
+	writeCounterVec(&buf, m.namespace+"_messages_sent_total", "Number of messages sent to Discord by kind.",
+		"kind", m.sent)
+	writeCounterVec(&buf, m.namespace+"_messages_failed_total", "Number of messages failed to send by kind.",
+		"kind", m.failed)
+	writeCounter(&buf, m.namespace+"_message_overflows_total", "Number of too long messages sent as a file.",
+		m.overflows)
+
+	_, err := io.WriteString(w, buf.String())
+
+	return err
+}
+
+func writeHeader(buf *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeCounterVec(buf *strings.Builder, name, help, label string, values map[[2]string]uint64) {
+	writeHeader(buf, name, "counter", help)
+
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(buf, "%s{%s} %d\n", name, labels(key[0], label, key[1]), values[key])
+	}
+}
+
+// writeCounter writes counter by bot. Counter without bots is written as
+// zero without labels.
+func writeCounter(buf *strings.Builder, name, help string, values map[string]uint64) {
+	writeHeader(buf, name, "counter", help)
+
+	if len(values) == 0 {
+		fmt.Fprintf(buf, "%s 0\n", name)
+
+		return
+	}
+
+	for _, bot := range sortedKeys(values) {
+		if bot == "" {
+			fmt.Fprintf(buf, "%s %d\n", name, values[bot])
+
+			continue
+		}
+
+		fmt.Fprintf(buf, "%s{%s} %d\n", name, labels(bot), values[bot])
+	}
+}
+
+func sortedKeys[K string | [2]string | [3]string, V any](values map[K]V) []K {
+	keys := make([]K, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	return keys
+}
+
+// labels formats label pairs. Bot label is added first if bot is not empty.
+func labels(bot string, pairs ...string) string {
+	var result []string
+
+	if bot != "" {
+		result = append(result, FieldBot+"="+quoteLabel(bot))
+	}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result = append(result, pairs[i]+"="+quoteLabel(pairs[i+1]))
+	}
+
+	return strings.Join(result, ",")
+}
+
+func quoteLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+
+	return `"` + value + `"`
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,115 @@
+package discordant_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+)
+
+func writeMetrics(t *testing.T, metrics *discordant.PrometheusMetrics) string {
+	t.Helper()
+
+	var buf strings.Builder
+
+	if err := metrics.Write(&buf); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	return buf.String()
+}
+
+func assertMetricLines(t *testing.T, exposition string, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("no line %q in:\n%s", line, exposition)
+		}
+	}
+}
+
+func TestPrometheusHistogram(t *testing.T) {
+	metrics := discordant.NewPrometheusMetrics("bot", 1, 0.5)
+
+	metrics.CommandInvoked("ping", discordant.OutcomeSuccess, 250*time.Millisecond)
+	metrics.CommandInvoked("ping", discordant.OutcomeError, 500*time.Millisecond)
+	metrics.CommandInvoked("ping", discordant.OutcomeSuccess, 2*time.Second)
+	metrics.CommandInvoked("ping", discordant.OutcomeRejected, 0)
+
+	exposition := writeMetrics(t, metrics)
+
+	assertMetricLines(t, exposition,
+		"# TYPE bot_command_duration_seconds histogram",
+		`bot_command_duration_seconds_bucket{command="ping",le="0.5"} 2`,
+		`bot_command_duration_seconds_bucket{command="ping",le="1"} 2`,
+		`bot_command_duration_seconds_bucket{command="ping",le="+Inf"} 3`,
+		`bot_command_duration_seconds_sum{command="ping"} 2.75`,
+		`bot_command_duration_seconds_count{command="ping"} 3`,
+		`bot_commands_total{command="ping",outcome="success"} 2`,
+		`bot_commands_total{command="ping",outcome="error"} 1`,
+		`bot_commands_total{command="ping",outcome="rejected"} 1`,
+	)
+
+	// Buckets are sorted, so le="0.5" goes before le="1".
+	if strings.Index(exposition, `le="0.5"`) > strings.Index(exposition, `le="1"`) {
+		t.Errorf("buckets are not sorted:\n%s", exposition)
+	}
+}
+
+func TestPrometheusCounters(t *testing.T) {
+	metrics := discordant.NewPrometheusMetrics("")
+
+	assertMetricLines(t, writeMetrics(t, metrics),
+		"discordant_unknown_commands_total 0",
+		"discordant_message_overflows_total 0",
+	)
+
+	metrics.AccessDenied("secret")
+	metrics.UnknownCommand()
+	metrics.UnknownCommand()
+	metrics.MessageSent("text")
+	metrics.MessageFailed("embed")
+	metrics.MessageOverflow()
+
+	assertMetricLines(t, writeMetrics(t, metrics),
+		"# TYPE discordant_access_denied_total counter",
+		`discordant_access_denied_total{command="secret"} 1`,
+		"discordant_unknown_commands_total 2",
+		`discordant_messages_sent_total{kind="text"} 1`,
+		`discordant_messages_failed_total{kind="embed"} 1`,
+		"discordant_message_overflows_total 1",
+	)
+}
+
+func TestPrometheusLabels(t *testing.T) {
+	metrics := discordant.NewPrometheusMetrics("")
+	bot := metrics.WithBot("a\"b\\c\nd")
+
+	bot.CommandInvoked(`say "hi"`, discordant.OutcomeSuccess, time.Second)
+	bot.UnknownCommand()
+	metrics.UnknownCommand()
+
+	assertMetricLines(t, writeMetrics(t, metrics),
+		`discordant_commands_total{bot="a\"b\\c\nd",command="say \"hi\"",outcome="success"} 1`,
+		`discordant_command_duration_seconds_count{bot="a\"b\\c\nd",command="say \"hi\""} 1`,
+		`discordant_unknown_commands_total{bot="a\"b\\c\nd"} 1`,
+		"discordant_unknown_commands_total 1",
+	)
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	metrics := discordant.NewPrometheusMetrics("")
+	metrics.UnknownCommand()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type %q", ct)
+	}
+
+	assertMetricLines(t, rec.Body.String(), "discordant_unknown_commands_total 1")
+}
@@ -0,0 +1,146 @@
+package discordant
+
+import (
+	"bufio"
+	"bytes"
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Notify sends message to the channel resolved by name from Config.Channels.
+// It allows to post messages outside command handlers, for example from
+// background jobs. Like Context.Send, message longer than Discord limit is
+// attached as file, optional param is the file name.
+func (d *Discordant) Notify(channelName, msg string, params ...string) error {
+	channelID, err := d.channelID(channelName)
+	if err != nil {
+		return fmt.Errorf("discordant notify: %w", err)
+	}
+
+	if err := d.send(ctx.Background(), channelID, msg, params...); err != nil {
+		return fmt.Errorf("discordant notify: %w", err)
+	}
+
+	return nil
+}
+
+// NotifyEmbed sends embed to the channel resolved by name from Config.Channels.
+func (d *Discordant) NotifyEmbed(channelName string, embed *discordgo.MessageEmbed) error {
+	channelID, err := d.channelID(channelName)
+	if err != nil {
+		return fmt.Errorf("discordant notify: %w", err)
+	}
+
+	if _, err := d.sendEmbed(ctx.Background(), channelID, embed); err != nil {
+		return fmt.Errorf("discordant notify: %w", err)
+	}
+
+	return nil
+}
+
+// NotifyJSON sends JSON message to the channel resolved by name from
+// Config.Channels. The input can be a string, error, or any type that can be
+// marshaled to JSON.
+func (d *Discordant) NotifyJSON(channelName string, rawmsg any, params ...string) error {
+	msg, err := formatJSON(rawmsg, false)
+	if err != nil {
+		return fmt.Errorf("discordant notify: %w", err)
+	}
+
+	return d.Notify(channelName, msg, params...)
+}
+
+// channelID resolves channel id by name from the current config.
+func (d *Discordant) channelID(name string) (string, error) {
+	id, ok := d.current().config.Channels[name]
+	if !ok || id == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+	}
+
+	return id, nil
+}
+
+// send sends message to discord channel. Message that is more than
+// 2000 characters is sent as file.
+func (d *Discordant) send(trace ctx.Context, channelID, msg string, params ...string) error {
+	// Send normal message.
+	if len([]rune(msg)) <= DiscordMaxMessageLenValidate {
+		_, err := d.sendText(trace, channelID, msg)
+
+		return err
+	}
+
+	// Message is too big. Attach as file.
+	d.metrics.MessageOverflow()
+
+	msg = strings.TrimPrefix(msg, "```json\n")
+	msg = strings.TrimSuffix(msg, "\n```")
+
+	var buf bytes.Buffer
+
+	fileName := "message.txt"
+
+	if len(params) > 0 {
+		fileName = params[0]
+	}
+
+	if _, err := buf.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	ms := &discordgo.MessageSend{Files: []*discordgo.File{
+		{Name: fileName, Reader: bufio.NewReader(&buf)},
+	}}
+
+	_, err := d.sendComplex(trace, channelID, ms)
+
+	return err
+}
+
+// sendMentions sends message that mentions only allowed users and roles.
+// It is used for messages with user supplied text. Message that is more than
+// 2000 characters is sent as file, which doesn't mention anyone.
+func (d *Discordant) sendMentions(
+	trace ctx.Context, channelID, msg string, allowed *discordgo.MessageAllowedMentions,
+) error {
+	if len([]rune(msg)) > DiscordMaxMessageLenValidate {
+		return d.send(trace, channelID, msg)
+	}
+
+	_, err := d.sendComplex(trace, channelID, &discordgo.MessageSend{Content: msg, AllowedMentions: allowed})
+
+	return err
+}
+
+// formatJSON converts string, error or any type that can be marshaled to
+// JSON to JSON message. The pretty parameter controls whether the JSON output
+// is formatted with indentation.
+func formatJSON(rawmsg any, pretty bool) (string, error) {
+	switch val := rawmsg.(type) {
+	case string:
+		return fmt.Sprintf(ResponseMessageFormatJSON, val), nil
+	case error:
+		return fmt.Sprintf(ResponseMessageFormatJSON, val.Error()), nil
+	}
+
+	var (
+		rawjson []byte
+		err     error
+	)
+
+	if pretty {
+		rawjson, err = json.MarshalIndent(rawmsg, "", "  ")
+	} else {
+		rawjson, err = json.Marshal(rawmsg)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidResponseMessageType, err)
+	}
+
+	return fmt.Sprintf(ResponseMessageFormatJSON, string(rawjson)), nil
+}
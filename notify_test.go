@@ -0,0 +1,113 @@
+package discordant_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestNotify(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if err := h.Bot.Notify(discordant.ChannelAdmin, "backup is done"); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	if msg := h.LastMessage(); msg.ChannelID != discordanttest.AdminChannelID || msg.Content != "backup is done" {
+		t.Errorf("message: %+v", msg)
+	}
+
+	long := strings.Repeat("a", discordant.DiscordMaxMessageLenValidate+1)
+
+	if err := h.Bot.Notify(discordant.ChannelGeneral, long, "report.txt"); err != nil {
+		t.Fatalf("Notify long message: %s", err)
+	}
+
+	h.AssertFile("report.txt", long)
+	h.AssertMessagesCount(2)
+
+	if err := h.Bot.Notify("missing", "lost"); !errors.Is(err, discordant.ErrUnknownChannel) {
+		t.Errorf("got %v, want %v", err, discordant.ErrUnknownChannel)
+	}
+
+	h.AssertMessagesCount(2)
+}
+
+func TestNotifyEmbed(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if err := h.Bot.NotifyEmbed(discordant.ChannelAdmin, &discordgo.MessageEmbed{Title: "Deploy"}); err != nil {
+		t.Fatalf("NotifyEmbed: %s", err)
+	}
+
+	h.AssertEmbed("Deploy")
+	h.AssertMessageIn(discordanttest.AdminChannelID)
+
+	err := h.Bot.NotifyEmbed("missing", &discordgo.MessageEmbed{Title: "Lost"})
+	if !errors.Is(err, discordant.ErrUnknownChannel) {
+		t.Errorf("got %v, want %v", err, discordant.ErrUnknownChannel)
+	}
+}
+
+func TestNotifyJSON(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{value: map[string]int{"jobs": 2}, expected: `{"jobs":2}`},
+		{value: "plain", expected: "plain"},
+		{value: errors.New("failed"), expected: "failed"},
+	}
+
+	for _, tt := range tests {
+		if err := h.Bot.NotifyJSON(discordant.ChannelGeneral, tt.value); err != nil {
+			t.Fatalf("NotifyJSON(%v): %s", tt.value, err)
+		}
+
+		h.AssertMessage(fmt.Sprintf(discordant.ResponseMessageFormatJSON, tt.expected))
+	}
+
+	err := h.Bot.NotifyJSON(discordant.ChannelGeneral, make(chan int))
+	if !errors.Is(err, discordant.ErrInvalidResponseMessageType) {
+		t.Errorf("got %v, want %v", err, discordant.ErrInvalidResponseMessageType)
+	}
+}
+
+func TestManagerNotify(t *testing.T) {
+	manager := discordant.NewManager()
+	sessions := make(map[string]*discordanttest.Session)
+
+	for _, name := range []string{"alpha", "beta"} {
+		sessions[name] = discordanttest.NewSession()
+
+		if _, err := manager.Add(name, discordanttest.NewConfig(), discordant.SetTransport(sessions[name])); err != nil {
+			t.Fatalf("Add %s: %s", name, err)
+		}
+	}
+
+	t.Cleanup(func() { _ = manager.Shutdown() })
+
+	if err := manager.Notify("beta", discordant.ChannelAdmin, "hello"); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	if messages := sessions["alpha"].Messages(); len(messages) != 0 {
+		t.Errorf("alpha messages: %+v", messages)
+	}
+
+	if messages := sessions["beta"].Messages(); len(messages) != 1 || messages[0].Content != "hello" ||
+		messages[0].ChannelID != discordanttest.AdminChannelID {
+		t.Errorf("beta messages: %+v", messages)
+	}
+
+	if err := manager.Notify("gamma", discordant.ChannelAdmin, "hello"); !errors.Is(err, discordant.ErrUnknownBot) {
+		t.Errorf("got %v, want %v", err, discordant.ErrUnknownBot)
+	}
+}
@@ -15,9 +15,110 @@ func SetSession(ses *discordgo.Session) Option {
 	}
 }
 
-// SetLogger sets logger to Discordant.
+// SetTransport sets alternative Discord API transport to Discordant.
+// Discordant doesn't close the transport on Close.
+func SetTransport(transport Transport) Option {
+	return func(d *Discordant) {
+		d.session = nopCloser{transport}
+	}
+}
+
+// SetID sets bot id to Discordant, so New doesn't retrieve bot account
+// from Discord API.
+func SetID(id string) Option {
+	return func(d *Discordant) {
+		d.id = id
+	}
+}
+
+// SetLogger sets logger to Discordant. Use NewSlogLogger to log to *slog.Logger.
 func SetLogger(logger Logger) Option {
 	return func(d *Discordant) {
 		d.logger = logger
 	}
 }
+
+// SetErrorHandler sets command error handler to Discordant.
+func SetErrorHandler(handler ErrorHandlerFunc) Option {
+	return func(d *Discordant) {
+		d.ErrorHandler = handler
+	}
+}
+
+// SetSuccessResponse sets default success response template to Discordant.
+func SetSuccessResponse(response Response) Option {
+	return func(d *Discordant) {
+		d.successResponse = response
+	}
+}
+
+// SetFailResponse sets default fail response template to Discordant.
+func SetFailResponse(response Response) Option {
+	return func(d *Discordant) {
+		d.failResponse = response
+	}
+}
+
+// SetExecutionPolicy sets command execution policy to Discordant.
+func SetExecutionPolicy(policy ExecutionPolicy) Option {
+	return func(d *Discordant) {
+		d.policy = policy
+	}
+}
+
+// SetMetrics sets metrics backend to Discordant.
+func SetMetrics(metrics Metrics) Option {
+	return func(d *Discordant) {
+		d.metrics = metrics
+	}
+}
+
+// SetAuditSinks sets audit sinks to Discordant. Audit is enabled per command
+// with MiddlewareAudit.
+func SetAuditSinks(sinks ...AuditSink) Option {
+	return func(d *Discordant) {
+		d.auditSinks = sinks
+	}
+}
+
+// SetAuditChannel enables posting of audit records to the channel resolved
+// by name from Config.Channels in addition to audit sinks. Records are posted
+// synchronously after the command handler returns and its concurrency slot is
+// released, so a slow post delays only the goroutine handling the message.
+func SetAuditChannel(channelName string) Option {
+	return func(d *Discordant) {
+		d.auditChannel = channelName
+	}
+}
+
+// SetTracer sets tracer to Discordant. Use NewOTLPTracer to export spans to
+// OpenTelemetry collector.
+func SetTracer(tracer Tracer) Option {
+	return func(d *Discordant) {
+		d.tracer = tracer
+	}
+}
+
+// SetIntents sets gateway intents to Discordant instead of computed minimal
+// intents. Discordant warns on Run if required privileged intents are missing.
+func SetIntents(intents discordgo.Intent) Option {
+	return func(d *Discordant) {
+		d.intents = &intents
+	}
+}
+
+// AddIntents adds gateway intents to computed minimal intents. It can be
+// used to receive events for custom handlers added with AddHandler.
+func AddIntents(intents discordgo.Intent) Option {
+	return func(d *Discordant) {
+		d.extraIntents |= intents
+	}
+}
+
+// SetStore sets key-value store of bot state to Discordant. Use NewFileStore
+// to keep the state between restarts.
+func SetStore(store Store) Option {
+	return func(d *Discordant) {
+		d.store = store
+	}
+}
@@ -0,0 +1,323 @@
+package discordant
+
+import (
+	"bytes"
+	ctx "context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/outdead/discordant/internal/otlp"
+)
+
+// OTLP tracer defaults.
+const (
+	DefaultOTLPServiceName   = "discordant"
+	DefaultOTLPBatchSize     = 512
+	DefaultOTLPFlushInterval = 5 * time.Second
+)
+
+// OTLPOption can be used to customize OTLPTracer.
+type OTLPOption func(t *OTLPTracer)
+
+// OTLPServiceName sets service.name resource attribute.
+func OTLPServiceName(name string) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.serviceName = name
+	}
+}
+
+// OTLPBatchSize sets the number of spans that triggers export.
+func OTLPBatchSize(size int) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.batchSize = size
+	}
+}
+
+// OTLPFlushInterval sets the interval of periodic export.
+func OTLPFlushInterval(interval time.Duration) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.flushInterval = interval
+	}
+}
+
+// OTLPClient sets http client used for export.
+func OTLPClient(client *http.Client) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.client = client
+	}
+}
+
+// OTLPHeaders sets additional export request headers such as authorization.
+func OTLPHeaders(headers map[string]string) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.headers = headers
+	}
+}
+
+// OTLPLogger sets logger for export errors.
+func OTLPLogger(logger Logger) OTLPOption {
+	return func(t *OTLPTracer) {
+		t.logger = logger
+	}
+}
+
+// OTLPTracer is Tracer implementation that exports spans to OpenTelemetry
+// collector with OTLP/HTTP JSON protocol. Spans are exported in batches.
+type OTLPTracer struct {
+	endpoint      string
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	client        *http.Client
+	headers       map[string]string
+	logger        Logger
+
+	mu    sync.Mutex
+	spans []otlp.Span
+
+	flush chan struct{}
+	done  chan struct{}
+	stop  sync.Once
+	wg    sync.WaitGroup
+}
+
+var _ Tracer = (*OTLPTracer)(nil)
+
+// NewOTLPTracer creates OTLPTracer that exports spans to collector endpoint such
+// as http://localhost:4318 and starts periodic export. The caller should call
+// Shutdown when finished.
+func NewOTLPTracer(endpoint string, options ...OTLPOption) *OTLPTracer {
+	t := OTLPTracer{
+		endpoint:      strings.TrimSuffix(endpoint, "/") + otlp.TracesPath,
+		serviceName:   DefaultOTLPServiceName,
+		batchSize:     DefaultOTLPBatchSize,
+		flushInterval: DefaultOTLPFlushInterval,
+		client:        http.DefaultClient,
+		logger:        NewDefaultLog(),
+		flush:         make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(&t)
+	}
+
+	t.wg.Add(1)
+
+	go t.run()
+
+	return &t
+}
+
+// Start starts span.
+func (t *OTLPTracer) Start(parent ctx.Context, name string, attrs ...Attribute) (ctx.Context, Span) {
+	span := otlpSpan{
+		tracer: t,
+		start:  time.Now(),
+		span: otlp.Span{
+			SpanID: randomHex(8),
+			Name:   name,
+			Kind:   otlp.SpanKindInternal,
+		},
+	}
+
+	if parentSpan, ok := parent.Value(otlpSpanKey{}).(*otlpSpan); ok {
+		span.span.TraceID = parentSpan.span.TraceID
+		span.span.ParentSpanID = parentSpan.span.SpanID
+	} else {
+		span.span.TraceID = randomHex(16)
+	}
+
+	if strings.HasPrefix(name, "discord.") {
+		span.span.Kind = otlp.SpanKindClient
+	}
+
+	span.SetAttributes(attrs...)
+
+	return ctx.WithValue(parent, otlpSpanKey{}, &span), &span
+}
+
+// Flush exports collected spans.
+func (t *OTLPTracer) Flush(c ctx.Context) error {
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	return t.export(c, spans)
+}
+
+// Shutdown stops periodic export and exports remaining spans. It is safe to
+// call Shutdown several times.
+func (t *OTLPTracer) Shutdown(c ctx.Context) error {
+	t.stop.Do(func() {
+		close(t.done)
+	})
+
+	t.wg.Wait()
+
+	return t.Flush(c)
+}
+
+func (t *OTLPTracer) run() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		case <-t.flush:
+		}
+
+		if err := t.Flush(ctx.Background()); err != nil {
+			t.logger.Errorf("discordant tracing: %s", err)
+		}
+	}
+}
+
+func (t *OTLPTracer) end(span otlp.Span) {
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	full := len(t.spans) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *OTLPTracer) export(c ctx.Context, spans []otlp.Span) error {
+	body, err := json.Marshal(otlp.ExportTraceServiceRequest{
+		ResourceSpans: []otlp.ResourceSpans{{
+			Resource: otlp.Resource{Attributes: []otlp.KeyValue{
+				keyValue("service.name", t.serviceName),
+			}},
+			ScopeSpans: []otlp.ScopeSpans{{
+				Scope: otlp.Scope{Name: "github.com/outdead/discordant"},
+				Spans: spans,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("export spans: %w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	return nil
+}
+
+type otlpSpanKey struct{}
+
+type otlpSpan struct {
+	tracer *OTLPTracer
+	start  time.Time
+
+	mu    sync.Mutex
+	span  otlp.Span
+	ended bool
+}
+
+func (s *otlpSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attr := range attrs {
+		s.span.Attributes = append(s.span.Attributes, keyValue(attr.Key, attr.Value))
+	}
+}
+
+func (s *otlpSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.span.Status = otlp.Status{Code: otlp.StatusCodeError, Message: err.Error()}
+}
+
+func (s *otlpSpan) End() {
+	s.mu.Lock()
+
+	if s.ended {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.ended = true
+	s.span.StartTimeUnixNano = strconv.FormatInt(s.start.UnixNano(), 10)
+	s.span.EndTimeUnixNano = strconv.FormatInt(time.Now().UnixNano(), 10)
+	span := s.span
+
+	s.mu.Unlock()
+
+	s.tracer.end(span)
+}
+
+func keyValue(key string, value interface{}) otlp.KeyValue {
+	var val otlp.AnyValue
+
+	switch v := value.(type) {
+	case string:
+		val.StringValue = &v
+	case bool:
+		val.BoolValue = &v
+	case int:
+		i := strconv.Itoa(v)
+		val.IntValue = &i
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		val.IntValue = &i
+	case float64:
+		val.DoubleValue = &v
+	default:
+		str := fmt.Sprint(v)
+		val.StringValue = &str
+	}
+
+	return otlp.KeyValue{Key: key, Value: val}
+}
+
+func randomHex(size int) string {
+	buf := make([]byte, size)
+
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,83 @@
+package discordant_test
+
+import (
+	ctx "context"
+	"errors"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestOTLPTracer(t *testing.T) {
+	collector := discordanttest.NewCollector()
+	defer collector.Close()
+
+	tracer := discordant.NewOTLPTracer(collector.URL, discordant.OTLPServiceName("test"))
+
+	h := discordanttest.New(t, nil, discordant.SetTracer(tracer))
+	h.Bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Success() })
+	h.Bot.ALL("boom", func(discordant.Context) error { return errors.New("boom") })
+
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.Exec(discordanttest.GeneralChannelID, "!boom")
+
+	if err := tracer.Shutdown(ctx.Background()); err != nil {
+		t.Fatalf("Shutdown: %s", err)
+	}
+
+	// Second shutdown must not panic on closed channel.
+	if err := tracer.Shutdown(ctx.Background()); err != nil {
+		t.Fatalf("second Shutdown: %s", err)
+	}
+
+	if services := collector.Services(); len(services) == 0 || services[0] != "test" {
+		t.Errorf("services %v", services)
+	}
+
+	commands := make(map[string]discordanttest.Span)
+
+	for _, span := range collector.SpansByName(discordant.SpanCommand) {
+		name, _ := span.Attributes[discordant.AttrCommand].(string)
+		commands[name] = span
+	}
+
+	if len(commands) != 2 {
+		t.Fatalf("command spans %v", commands)
+	}
+
+	handlers := collector.SpansByName(discordant.SpanHandler)
+	if len(handlers) != 2 {
+		t.Fatalf("handler spans %v", handlers)
+	}
+
+	for _, span := range handlers {
+		var parent discordanttest.Span
+
+		for _, command := range commands {
+			if command.SpanID == span.ParentSpanID {
+				parent = command
+			}
+		}
+
+		if parent.SpanID == "" {
+			t.Fatalf("handler span %s has unknown parent %s", span.SpanID, span.ParentSpanID)
+		}
+
+		if span.TraceID != parent.TraceID {
+			t.Errorf("handler trace %s, command trace %s", span.TraceID, parent.TraceID)
+		}
+	}
+
+	if commands["ping"].ParentSpanID != "" || commands["ping"].Error != "" {
+		t.Errorf("ping span: %+v", commands["ping"])
+	}
+
+	if commands["boom"].Error != "boom" {
+		t.Errorf("boom span error %q, want boom", commands["boom"].Error)
+	}
+
+	if commands["ping"].TraceID == commands["boom"].TraceID {
+		t.Errorf("commands share trace %s", commands["ping"].TraceID)
+	}
+}
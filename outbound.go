@@ -0,0 +1,129 @@
+package discordant
+
+import (
+	ctx "context"
+	"errors"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// All outbound Discord calls go through these functions, so that they are
+// instrumented in one place.
+
+func (d *Discordant) sendText(trace ctx.Context, channelID, content string) (*discordgo.Message, error) {
+	span := d.startSendSpan(trace, SpanSend, MessageKindText, channelID)
+
+	msg, err := d.session.ChannelMessageSend(channelID, content)
+
+	d.observeMessage(span, MessageKindText, err)
+
+	return msg, err
+}
+
+func (d *Discordant) sendComplex(
+	trace ctx.Context, channelID string, data *discordgo.MessageSend,
+) (*discordgo.Message, error) {
+	kind := MessageKindText
+
+	switch {
+	case len(data.Files) != 0:
+		kind = MessageKindFile
+	case len(data.Embeds) != 0:
+		kind = MessageKindEmbed
+	}
+
+	span := d.startSendSpan(trace, SpanSend, kind, channelID)
+
+	msg, err := d.session.ChannelMessageSendComplex(channelID, data)
+
+	d.observeMessage(span, kind, err)
+
+	return msg, err
+}
+
+func (d *Discordant) sendEmbed(
+	trace ctx.Context, channelID string, embed *discordgo.MessageEmbed,
+) (*discordgo.Message, error) {
+	span := d.startSendSpan(trace, SpanSend, MessageKindEmbed, channelID)
+
+	msg, err := d.session.ChannelMessageSendEmbed(channelID, embed)
+
+	d.observeMessage(span, MessageKindEmbed, err)
+
+	return msg, err
+}
+
+func (d *Discordant) addReaction(trace ctx.Context, channelID, messageID, emoji string) error {
+	span := d.startSendSpan(trace, SpanReaction, MessageKindReaction, channelID)
+
+	err := d.session.MessageReactionAdd(channelID, messageID, emoji)
+
+	d.observeMessage(span, MessageKindReaction, err)
+
+	return err
+}
+
+func (d *Discordant) editComplex(trace ctx.Context, data *discordgo.MessageEdit) (*discordgo.Message, error) {
+	span := d.startSendSpan(trace, SpanEdit, MessageKindEdit, data.Channel)
+
+	msg, err := d.session.ChannelMessageEditComplex(data)
+
+	d.observeMessage(span, MessageKindEdit, err)
+
+	return msg, err
+}
+
+func (d *Discordant) respondInteraction(
+	trace ctx.Context, interaction *discordgo.Interaction, resp *discordgo.InteractionResponse,
+) error {
+	span := d.startSendSpan(trace, SpanRespond, MessageKindResponse, interaction.ChannelID)
+
+	err := d.session.InteractionRespond(interaction, resp)
+
+	d.observeMessage(span, MessageKindResponse, err)
+
+	return err
+}
+
+func (d *Discordant) createDM(trace ctx.Context, userID string) (*discordgo.Channel, error) {
+	_, span := d.tracer.Start(trace, SpanDM, Attr(AttrUser, userID))
+
+	channel, err := d.session.UserChannelCreate(userID)
+
+	endSpan(span, err)
+
+	return channel, err
+}
+
+func (d *Discordant) startSendSpan(trace ctx.Context, name, kind, channelID string) Span {
+	_, span := d.tracer.Start(trace, name, Attr(AttrMessageKind, kind), Attr(AttrChannel, channelID))
+
+	return span
+}
+
+func (d *Discordant) observeMessage(span Span, kind string, err error) {
+	endSpan(span, err)
+
+	if err != nil {
+		d.metrics.MessageFailed(kind)
+
+		return
+	}
+
+	d.metrics.MessageSent(kind)
+}
+
+// isRejected reports whether Discord rejected the request with a client error
+// other than rate limit, so that repeating the request fails again.
+func isRejected(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+
+	code := restErr.Response.StatusCode
+
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError &&
+		code != http.StatusTooManyRequests
+}
@@ -0,0 +1,123 @@
+package discordant
+
+import (
+	"strings"
+	"sync"
+)
+
+// registry is a thread-safe commands storage.
+type registry struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+func newRegistry() *registry {
+	return &registry{commands: make(map[string]Command)}
+}
+
+// set calls prepare and stores the command under the lock.
+func (r *registry) set(command Command, prepare func(command *Command)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prepare(&command)
+
+	r.commands[command.Name] = command
+}
+
+// update calls before and then fn for every command and stores the result.
+// Everything is done under the lock, so readers never see changes of before
+// without changes of commands.
+func (r *registry) update(before func(), fn func(command *Command)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before()
+
+	for name, command := range r.commands {
+		fn(&command)
+		r.commands[name] = command
+	}
+}
+
+func (r *registry) remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.commands[name]; !ok {
+		return ErrCommandNotFound
+	}
+
+	delete(r.commands, name)
+
+	return nil
+}
+
+// setDisabled changes the command state and returns the previous one.
+func (r *registry) setDisabled(name string, disabled bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	command, ok := r.commands[name]
+	if !ok {
+		return false, ErrCommandNotFound
+	}
+
+	previous := command.Disabled
+
+	command.Disabled = disabled
+	r.commands[name] = command
+
+	return previous, nil
+}
+
+// find returns command by received message.
+func (r *registry) find(message string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Command without args.
+	if command, ok := r.commands[message]; ok {
+		return command, true
+	}
+
+	// Find commands with args. The longest name wins, so intersecting
+	// commands such as `rules` and `rules set` are routed correctly.
+	var (
+		found Command
+		ok    bool
+	)
+
+	for name, command := range r.commands {
+		if !strings.HasPrefix(message, name+DefaultCommandDelimiter) {
+			continue
+		}
+
+		if !ok || len(name) > len(found.Name) {
+			found, ok = command, true
+		}
+	}
+
+	if !ok {
+		return Command{}, false
+	}
+
+	found.Arg = strings.TrimSpace(strings.TrimPrefix(message, found.Name))
+
+	return found, true
+}
+
+// all returns copy of commands map.
+func (r *registry) all() map[string]Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make(map[string]Command, len(r.commands))
+
+	for name, command := range r.commands {
+		command.Access = append([]string(nil), command.Access...)
+		commands[name] = command
+	}
+
+	return commands
+}
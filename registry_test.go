@@ -0,0 +1,67 @@
+package discordant_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestGetCommand(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	handler := func(ctx discordant.Context) error { return ctx.Success() }
+
+	for _, name := range []string{"rule", "rules", "rules set", "rules set default"} {
+		h.Bot.ALL(name, handler)
+	}
+
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "rules", expected: "rules"},
+		{message: "rule", expected: "rule"},
+		{message: "rules now", expected: "rules"},
+		{message: "rule 1", expected: "rule"},
+		{message: "rules set", expected: "rules set"},
+		{message: "rules set x", expected: "rules set"},
+		{message: "rules set default", expected: "rules set default"},
+		{message: "rules set default text", expected: "rules set default"},
+		{message: "rules setx", expected: "rules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			command, err := h.Bot.GetCommand(tt.message)
+			if err != nil {
+				t.Fatalf("GetCommand: %s", err)
+			}
+
+			if command.Name != tt.expected {
+				t.Errorf("got %q, want %q", command.Name, tt.expected)
+			}
+		})
+	}
+
+	for _, message := range []string{"ru", "rulesx", "ruleset", ""} {
+		if command, err := h.Bot.GetCommand(message); !errors.Is(err, discordant.ErrCommandNotFound) {
+			t.Errorf("GetCommand(%q) = %v, %v, want ErrCommandNotFound", message, command, err)
+		}
+	}
+}
+
+func TestGetCommandDisabled(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.ALL("rules", func(ctx discordant.Context) error { return ctx.Success() })
+
+	if err := h.Bot.Disable("rules"); err != nil {
+		t.Fatal(err)
+	}
+
+	command, err := h.Bot.GetCommand("rules set x")
+	if !errors.Is(err, discordant.ErrCommandDisabled) || command == nil || command.Name != "rules" {
+		t.Errorf("GetCommand = %v, %v, want disabled rules", command, err)
+	}
+}
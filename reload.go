@@ -0,0 +1,288 @@
+package discordant
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultConfigWatchInterval is the default interval of config file polling.
+const DefaultConfigWatchInterval = 5 * time.Second
+
+// state is the part of Discordant that is swapped atomically on Reload.
+type state struct {
+	config      *Config
+	accessOrder []string
+}
+
+func newState(cfg *Config) *state {
+	st := state{config: cfg.clone()}
+
+	if len(st.config.AccessOrder) == 0 {
+		st.accessOrder = []string{ChannelGeneral, ChannelAdmin}
+	} else {
+		st.accessOrder = append([]string(nil), st.config.AccessOrder...)
+	}
+
+	return &st
+}
+
+// commandAccess returns command access list sorted by access order.
+func (st *state) commandAccess(command *Command) []string {
+	if command.accessAll {
+		return append([]string(nil), st.accessOrder...)
+	}
+
+	buf := make(map[string]struct{}, len(command.declaredAccess))
+
+	for _, channel := range command.declaredAccess {
+		buf[channel] = struct{}{}
+	}
+
+	access := make([]string, 0, len(st.accessOrder))
+
+	for _, channel := range st.accessOrder {
+		if _, ok := buf[channel]; ok {
+			access = append(access, channel)
+		}
+	}
+
+	return access
+}
+
+// checkAccess returns true if the channel id is one of the channels or no
+// channels are given.
+func (st *state) checkAccess(id string, channels ...string) bool {
+	if len(channels) == 0 {
+		return true
+	}
+
+	for _, channel := range channels {
+		if id == st.config.Channels[channel] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cfg *Config) clone() *Config {
+	clone := *cfg
+
+	clone.AccessOrder = append([]string(nil), cfg.AccessOrder...)
+	clone.Shards = append([]int(nil), cfg.Shards...)
+	clone.Channels = make(map[string]string, len(cfg.Channels))
+
+	for name, id := range cfg.Channels {
+		clone.Channels[name] = id
+	}
+
+	return &clone
+}
+
+func (d *Discordant) current() *state {
+	return d.state.Load()
+}
+
+// Config returns a copy of the current config.
+func (d *Discordant) Config() *Config {
+	return d.current().config.clone()
+}
+
+// Reload validates and atomically swaps config and recalculates access lists
+// of commands. Prefix, safemode, channels and access order are applied
+// without restart. Commands in progress are not interrupted. Token can't be
+// changed without restart.
+func (d *Discordant) Reload(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("discordant: reload: %w", err)
+	}
+
+	next := newState(cfg)
+
+	var prev *state
+
+	// Config and access lists of commands are published together under the
+	// registry lock, so commands are never checked against mixed config.
+	d.commands.update(func() {
+		prev = d.state.Swap(next)
+	}, func(command *Command) {
+		command.Access = next.commandAccess(command)
+	})
+
+	if next.config.Token != "" && next.config.Token != prev.config.Token {
+		d.logger.Warningf("discordant: reload: token change requires restart")
+	}
+
+	if !reflect.DeepEqual(prev.config.sharding(), next.config.sharding()) {
+		d.logger.Warningf("discordant: reload: shards change requires restart")
+	}
+
+	changes := diffConfig(prev, next)
+	if len(changes) == 0 {
+		d.logger.Infof("discordant: config reloaded without changes")
+
+		return nil
+	}
+
+	d.logger.Infof("discordant: config reloaded: %s", strings.Join(changes, "; "))
+
+	return nil
+}
+
+// ReloadFile loads config from file with LoadConfig and reloads it.
+func (d *Discordant) ReloadFile(path string) error {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("discordant: reload: %w", err)
+	}
+
+	return d.Reload(cfg)
+}
+
+// WatchConfig polls config file modification time with the interval and
+// reloads config when it is changed. It returns function that stops watching.
+func (d *Discordant) WatchConfig(path string, interval time.Duration) func() {
+	if interval <= 0 {
+		interval = DefaultConfigWatchInterval
+	}
+
+	var modTime time.Time
+
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				d.logger.Errorf("discordant: watch config: %s", err)
+
+				continue
+			}
+
+			if info.ModTime().Equal(modTime) {
+				continue
+			}
+
+			modTime = info.ModTime()
+
+			if err := d.ReloadFile(path); err != nil {
+				d.logger.Errorf("%s", err)
+			}
+		}
+	}()
+
+	return stopOnce(done)
+}
+
+// ReloadOnSignal reloads config from file when process receives one of the
+// signals, SIGHUP by default. It returns function that stops listening.
+func (d *Discordant) ReloadOnSignal(path string, signals ...os.Signal) func() {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				d.logger.Infof("discordant: received %s, reloading config", sig)
+
+				if err := d.ReloadFile(path); err != nil {
+					d.logger.Errorf("%s", err)
+				}
+			}
+		}
+	}()
+
+	return stopOnce(done)
+}
+
+func stopOnce(done chan struct{}) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// diffConfig returns human-readable list of changes.
+func diffConfig(prev, next *state) []string {
+	var changes []string
+
+	if prev.config.Prefix != next.config.Prefix {
+		changes = append(changes, fmt.Sprintf("prefix %q -> %q", prev.config.Prefix, next.config.Prefix))
+	}
+
+	if prev.config.Safemode != next.config.Safemode {
+		changes = append(changes, fmt.Sprintf("safemode %t -> %t", prev.config.Safemode, next.config.Safemode))
+	}
+
+	names := make(map[string]struct{}, len(prev.config.Channels)+len(next.config.Channels))
+
+	for name := range prev.config.Channels {
+		names[name] = struct{}{}
+	}
+
+	for name := range next.config.Channels {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		prevID, inPrev := prev.config.Channels[name]
+		nextID, inNext := next.config.Channels[name]
+
+		switch {
+		case !inPrev:
+			changes = append(changes, fmt.Sprintf("channel %s added: %s", name, nextID))
+		case !inNext:
+			changes = append(changes, fmt.Sprintf("channel %s removed", name))
+		case prevID != nextID:
+			changes = append(changes, fmt.Sprintf("channel %s %s -> %s", name, prevID, nextID))
+		}
+	}
+
+	if !reflect.DeepEqual(prev.accessOrder, next.accessOrder) {
+		changes = append(changes, fmt.Sprintf("access order %v -> %v", prev.accessOrder, next.accessOrder))
+	}
+
+	return changes
+}
@@ -0,0 +1,211 @@
+package discordant_test
+
+import (
+	ctx "context"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestReloadAccess(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Success() })
+
+	cfg := discordanttest.NewConfig()
+	cfg.AccessOrder = []string{discordant.ChannelAdmin}
+
+	if err := h.Bot.Reload(cfg); err != nil {
+		t.Fatalf("Reload: %s", err)
+	}
+
+	if access := h.Bot.Commands()["ping"].Access; !reflect.DeepEqual(access, cfg.AccessOrder) {
+		t.Errorf("access %v, want %v", access, cfg.AccessOrder)
+	}
+}
+
+func TestReloadConcurrentAdd(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	first := discordanttest.NewConfig()
+	first.AccessOrder = []string{discordant.ChannelGeneral, discordant.ChannelAdmin}
+
+	last := discordanttest.NewConfig()
+	last.AccessOrder = []string{discordant.ChannelAdmin}
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			cfg := first
+			if i%2 == 1 {
+				cfg = last
+			}
+
+			if err := h.Bot.Reload(cfg); err != nil {
+				t.Errorf("Reload: %s", err)
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			h.Bot.ALL(fmt.Sprintf("cmd%d", i), func(ctx discordant.Context) error { return ctx.Success() })
+		}
+	}()
+
+	wg.Wait()
+
+	for name, command := range h.Bot.Commands() {
+		if !reflect.DeepEqual(command.Access, last.AccessOrder) {
+			t.Errorf("%s access %v, want %v", name, command.Access, last.AccessOrder)
+		}
+	}
+}
+
+// reloadTracer reloads config when the command is routed, that is after
+// prefix is checked and before access is checked.
+type reloadTracer struct {
+	once   sync.Once
+	reload func()
+}
+
+func (r *reloadTracer) Start(
+	parent ctx.Context, name string, _ ...discordant.Attribute,
+) (ctx.Context, discordant.Span) {
+	if name == discordant.SpanRoute {
+		r.once.Do(r.reload)
+	}
+
+	return parent, reloadSpan{}
+}
+
+type reloadSpan struct{}
+
+func (reloadSpan) SetAttributes(...discordant.Attribute) {}
+func (reloadSpan) RecordError(error)                     {}
+func (reloadSpan) End()                                  {}
+
+func TestReloadDuringMessage(t *testing.T) {
+	tracer := &reloadTracer{}
+
+	h := discordanttest.New(t, nil, discordant.SetTracer(tracer))
+	h.Bot.GENERAL("ping", func(ctx discordant.Context) error { return ctx.Send("pong") })
+
+	next := discordanttest.NewConfig()
+	next.Prefix = "?"
+	next.Channels[discordant.ChannelGeneral] = "700000000000000099"
+
+	tracer.reload = func() {
+		if err := h.Bot.Reload(next); err != nil {
+			t.Errorf("Reload: %s", err)
+		}
+	}
+
+	// Message is handled with the config it was received with.
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessage("pong")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertNoMessages()
+}
+
+// waitPrefix waits until bot config has the prefix.
+func waitPrefix(t *testing.T, bot *discordant.Discordant, prefix string) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+
+	for bot.Config().Prefix != prefix {
+		if time.Now().After(deadline) {
+			t.Fatalf("prefix is %q, want %q", bot.Config().Prefix, prefix)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+const reloadConfig = `
+prefix: "%s"
+channels:
+  general: "700000000000000002"
+  admin: "700000000000000003"
+`
+
+func TestWatchConfig(t *testing.T) {
+	path := writeConfig(t, "config.yaml", fmt.Sprintf(reloadConfig, "!"))
+
+	h := discordanttest.New(t, nil)
+
+	stop := h.Bot.WatchConfig(path, 10*time.Millisecond)
+	t.Cleanup(stop)
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfig, "?")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modification time is moved, so the change is seen on file systems with
+	// coarse timestamps.
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	waitPrefix(t, h.Bot, "?")
+
+	stop()
+	stop()
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(reloadConfig, "$")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime = modTime.Add(time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if prefix := h.Bot.Config().Prefix; prefix != "?" {
+		t.Errorf("config is reloaded after stop, prefix %q", prefix)
+	}
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent to own process on windows")
+	}
+
+	path := writeConfig(t, "config.yaml", fmt.Sprintf(reloadConfig, "?"))
+
+	h := discordanttest.New(t, nil)
+
+	stop := h.Bot.ReloadOnSignal(path, syscall.SIGUSR1)
+	t.Cleanup(stop)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := process.Signal(syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	waitPrefix(t, h.Bot, "?")
+}
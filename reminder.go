@@ -0,0 +1,479 @@
+package discordant
+
+import (
+	ctx "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CommandRemind is the name of the reminders command.
+const CommandRemind = "remind"
+
+// Reminder defaults.
+const (
+	DefaultReminderLimit         = 25
+	DefaultReminderMaxDuration   = 365 * 24 * time.Hour
+	DefaultReminderRetryInterval = time.Minute
+	DefaultReminderMaxAttempts   = 10
+)
+
+// reminderMaxRetryInterval limits the interval between delivery attempts.
+const reminderMaxRetryInterval = time.Hour
+
+// reminderMaxTextLen limits reminder text, so that delivered reminder fits
+// in one message.
+const reminderMaxTextLen = 1500
+
+// Reminder is a message that is delivered to the user at due time.
+type Reminder struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	GuildID   string    `json:"guild_id,omitempty"`
+	ChannelID string    `json:"channel_id"`
+	DM        bool      `json:"dm,omitempty"`
+	Text      string    `json:"text"`
+	Due       time.Time `json:"due"`
+	Created   time.Time `json:"created"`
+}
+
+// ReminderStore keeps reminders, so that they survive restarts.
+type ReminderStore interface {
+	SaveReminder(reminder Reminder) error
+	DeleteReminder(id string) error
+	Reminders() ([]Reminder, error)
+}
+
+// ReminderOption can be used to customize reminders.
+type ReminderOption func(r *reminders)
+
+// ReminderLimit sets the maximum number of pending reminders per user.
+func ReminderLimit(limit int) ReminderOption {
+	return func(r *reminders) {
+		r.limit = limit
+	}
+}
+
+// ReminderRetryInterval sets the first interval between delivery attempts
+// of the reminder that failed to be sent. The interval doubles with every
+// attempt up to one hour.
+func ReminderRetryInterval(interval time.Duration) ReminderOption {
+	return func(r *reminders) {
+		r.retryInterval = interval
+	}
+}
+
+// ReminderMaxAttempts sets the maximum number of delivery attempts. The
+// reminder is dropped when all attempts fail.
+func ReminderMaxAttempts(attempts int) ReminderOption {
+	return func(r *reminders) {
+		r.maxAttempts = attempts
+	}
+}
+
+// ReminderMaxDuration sets the maximum duration of reminder.
+func ReminderMaxDuration(duration time.Duration) ReminderOption {
+	return func(r *reminders) {
+		r.maxDuration = duration
+	}
+}
+
+// AddReminderCommands adds `remind` route handler available to everyone:
+//
+//	remind in 2h check backups     reminds in the channel
+//	remind dm in 1 day renew cert  reminds by direct message
+//	remind list                    lists own reminders
+//	remind cancel <id>             cancels own reminder
+//
+// Reminders are kept in the store and are delivered after Run until Close.
+// If store is nil, reminders are kept in Discordant Store.
+func (d *Discordant) AddReminderCommands(store ReminderStore, options ...ReminderOption) {
+	if store == nil {
+		store = NewStoreReminderStore(d.store)
+	}
+
+	r := reminders{
+		discordant:    d,
+		store:         store,
+		limit:         DefaultReminderLimit,
+		maxDuration:   DefaultReminderMaxDuration,
+		retryInterval: DefaultReminderRetryInterval,
+		maxAttempts:   DefaultReminderMaxAttempts,
+		timers:        make(map[string]*time.Timer),
+	}
+
+	for _, option := range options {
+		option(&r)
+	}
+
+	d.reminders = &r
+
+	if d.scheduler.running() {
+		if err := r.start(); err != nil {
+			d.logger.Error(err)
+		}
+	}
+
+	d.ALL(CommandRemind, r.handler, MiddlewareDescription("reminds you later: remind [dm] in <duration> <text>"))
+}
+
+// reminders schedules delivery of stored reminders.
+type reminders struct {
+	discordant    *Discordant
+	store         ReminderStore
+	limit         int
+	maxDuration   time.Duration
+	retryInterval time.Duration
+	maxAttempts   int
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	started bool
+
+	// createMu serializes reminder creation, so that the user limit and
+	// reminder ids are checked against the stored reminders.
+	createMu sync.Mutex
+}
+
+// start schedules stored reminders. Overdue reminders are delivered at once.
+func (r *reminders) start() error {
+	stored, err := r.store.Reminders()
+	if err != nil {
+		return fmt.Errorf("discordant reminders: load: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started = true
+
+	for _, reminder := range stored {
+		r.schedule(reminder)
+	}
+
+	return nil
+}
+
+// stop stops delivery timers. Reminders stay in the store.
+func (r *reminders) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started = false
+
+	for id, timer := range r.timers {
+		timer.Stop()
+		delete(r.timers, id)
+	}
+}
+
+// schedule must be called under the lock.
+func (r *reminders) schedule(reminder Reminder) {
+	r.timers[reminder.ID] = time.AfterFunc(time.Until(reminder.Due), func() {
+		r.deliver(reminder, 0)
+	})
+}
+
+// deliver sends the reminder and deletes it from the store. Failed delivery
+// is retried with growing interval, so temporary Discord errors don't lose
+// reminders. The reminder is dropped if Discord rejects it, for example when
+// the channel is deleted, or when attempts are exhausted.
+func (r *reminders) deliver(reminder Reminder, attempt int) {
+	r.mu.Lock()
+	_, ok := r.timers[reminder.ID]
+	r.mu.Unlock()
+
+	if !ok {
+		// Canceled or stopped.
+		return
+	}
+
+	d := r.discordant
+	logger := WithFields(d.logger, Fields{FieldUser: reminder.UserID, FieldChannel: reminder.ChannelID})
+
+	if err := r.send(reminder); err != nil {
+		if attempt+1 < r.maxAttempts && !isRejected(err) {
+			delay := min(r.retryInterval<<min(attempt, 16), reminderMaxRetryInterval)
+
+			logger.Errorf("discordant reminders: deliver %s: %s, retry in %s", reminder.ID, err, delay)
+
+			r.mu.Lock()
+			if _, ok := r.timers[reminder.ID]; ok {
+				r.timers[reminder.ID] = time.AfterFunc(delay, func() {
+					r.deliver(reminder, attempt+1)
+				})
+			}
+			r.mu.Unlock()
+
+			return
+		}
+
+		logger.Errorf("discordant reminders: deliver %s: %s, reminder is dropped after %d attempts",
+			reminder.ID, err, attempt+1)
+	}
+
+	r.mu.Lock()
+	delete(r.timers, reminder.ID)
+	r.mu.Unlock()
+
+	if err := r.store.DeleteReminder(reminder.ID); err != nil {
+		logger.Errorf("discordant reminders: delete %s: %s", reminder.ID, err)
+	}
+}
+
+func (r *reminders) send(reminder Reminder) error {
+	d := r.discordant
+
+	// Reminder text is user input, so only the owner may be mentioned.
+	if !reminder.DM {
+		msg := fmt.Sprintf("<@%s> reminder: %s", reminder.UserID, reminder.Text)
+
+		return d.sendMentions(ctx.Background(), reminder.ChannelID, msg,
+			&discordgo.MessageAllowedMentions{Users: []string{reminder.UserID}})
+	}
+
+	channel, err := d.createDM(ctx.Background(), reminder.UserID)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Reminder: %s\nset %s in <#%s>", reminder.Text,
+		discordTimestamp(reminder.Created), reminder.ChannelID)
+
+	return d.sendMentions(ctx.Background(), channel.ID, msg, &discordgo.MessageAllowedMentions{})
+}
+
+func (r *reminders) handler(ctx Context) error {
+	args := strings.Fields(ctx.QueryString())
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		return r.list(ctx)
+	case len(args) == 2 && args[0] == "cancel":
+		return r.cancel(ctx, args[1])
+	}
+
+	dm := len(args) > 0 && args[0] == "dm"
+	if dm {
+		args = args[1:]
+	}
+
+	if len(args) < 3 || args[0] != "in" {
+		return NewUserError("usage: remind [dm] in <duration> <text>, remind list, remind cancel <id>")
+	}
+
+	duration, words, err := cutDuration(args[1:])
+	if err != nil {
+		return WrapUserError("invalid duration, use for example 2h, 1h30m or 3 days", err)
+	}
+
+	if len(words) == 0 {
+		return NewUserError("reminder text is required")
+	}
+
+	text := strings.Join(words, " ")
+	if len([]rune(text)) > reminderMaxTextLen {
+		return NewUserError(fmt.Sprintf("reminder text can't be longer than %d characters", reminderMaxTextLen))
+	}
+
+	if duration > r.maxDuration {
+		return NewUserError(fmt.Sprintf("reminder can't be longer than %s", r.maxDuration))
+	}
+
+	request := ctx.Request()
+
+	r.createMu.Lock()
+	defer r.createMu.Unlock()
+
+	own, err := r.own(request.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(own) >= r.limit {
+		return NewUserError(fmt.Sprintf("you can't have more than %d reminders", r.limit))
+	}
+
+	id, err := r.newID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reminder := Reminder{
+		ID:        id,
+		UserID:    request.Author.ID,
+		GuildID:   request.GuildID,
+		ChannelID: request.ChannelID,
+		DM:        dm,
+		Text:      text,
+		Due:       now.Add(duration),
+		Created:   now,
+	}
+
+	if err := r.store.SaveReminder(reminder); err != nil {
+		return fmt.Errorf("discordant reminders: save: %w", err)
+	}
+
+	r.mu.Lock()
+	if r.started {
+		r.schedule(reminder)
+	}
+	r.mu.Unlock()
+
+	return ctx.Send(fmt.Sprintf("Reminder `%s` set for %s", reminder.ID, discordTimestamp(reminder.Due)))
+}
+
+func (r *reminders) list(ctx Context) error {
+	own, err := r.own(ctx.Request().Author.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(own) == 0 {
+		return ctx.Send("You have no reminders")
+	}
+
+	var buf strings.Builder
+
+	for _, reminder := range own {
+		fmt.Fprintf(&buf, "`%s` %s %s", reminder.ID, discordTimestamp(reminder.Due), reminder.Text)
+
+		if reminder.DM {
+			buf.WriteString(" (dm)")
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return r.discordant.sendMentions(ctx.StdContext(), ctx.ChannelID(), buf.String(), &discordgo.MessageAllowedMentions{})
+}
+
+func (r *reminders) cancel(ctx Context, id string) error {
+	own, err := r.own(ctx.Request().Author.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range own {
+		if reminder.ID != id {
+			continue
+		}
+
+		r.mu.Lock()
+		if timer, ok := r.timers[id]; ok {
+			timer.Stop()
+			delete(r.timers, id)
+		}
+		r.mu.Unlock()
+
+		if err := r.store.DeleteReminder(id); err != nil {
+			return fmt.Errorf("discordant reminders: delete: %w", err)
+		}
+
+		return ctx.Success()
+	}
+
+	return WrapUserError(fmt.Sprintf("reminder %q not found", id), ErrUnknownReminder)
+}
+
+// own returns reminders of the user sorted by due time.
+func (r *reminders) own(userID string) ([]Reminder, error) {
+	stored, err := r.store.Reminders()
+	if err != nil {
+		return nil, fmt.Errorf("discordant reminders: load: %w", err)
+	}
+
+	own := make([]Reminder, 0, len(stored))
+
+	for _, reminder := range stored {
+		if reminder.UserID == userID {
+			own = append(own, reminder)
+		}
+	}
+
+	sort.Slice(own, func(i, j int) bool {
+		return own[i].Due.Before(own[j].Due)
+	})
+
+	return own, nil
+}
+
+// newID returns short reminder id that is not used by stored reminders. It
+// must be called under createMu.
+func (r *reminders) newID() (string, error) {
+	stored, err := r.store.Reminders()
+	if err != nil {
+		return "", fmt.Errorf("discordant reminders: load: %w", err)
+	}
+
+	used := make(map[string]bool, len(stored))
+	for _, reminder := range stored {
+		used[reminder.ID] = true
+	}
+
+	for {
+		if id := randomHex(4); !used[id] {
+			return id, nil
+		}
+	}
+}
+
+// storeReminderStore keeps reminders in Store under `reminders/` prefix.
+type storeReminderStore struct {
+	store Store
+}
+
+// NewStoreReminderStore creates ReminderStore that keeps reminders as JSON
+// values in the store.
+func NewStoreReminderStore(store Store) ReminderStore {
+	return &storeReminderStore{store: NewPrefixStore(store, "reminders/")}
+}
+
+func (s *storeReminderStore) SaveReminder(reminder Reminder) error {
+	data, err := json.Marshal(reminder)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Set(reminder.ID, data, 0)
+}
+
+func (s *storeReminderStore) DeleteReminder(id string) error {
+	return s.store.Delete(id)
+}
+
+func (s *storeReminderStore) Reminders() ([]Reminder, error) {
+	keys, err := s.store.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]Reminder, 0, len(keys))
+
+	for _, key := range keys {
+		data, err := s.store.Get(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		var reminder Reminder
+		if err := json.Unmarshal(data, &reminder); err != nil {
+			return nil, fmt.Errorf("parse reminder %s: %w", key, err)
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
@@ -0,0 +1,208 @@
+package discordant_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+// waitMessage waits for message that contains substr.
+func waitMessage(t *testing.T, h *discordanttest.Harness, substr string) discordanttest.Message {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		for _, msg := range h.Messages() {
+			if strings.Contains(msg.Content, substr) {
+				return msg
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("no message contains %q", substr)
+
+	return discordanttest.Message{}
+}
+
+func TestRemindMentionsOnlyOwner(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddReminderCommands(nil)
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind in 1s @everyone check backups")
+	h.AssertMessageContains("Reminder `")
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind list")
+
+	list := h.LastMessage()
+	if list.AllowedMentions == nil || len(list.AllowedMentions.Parse) != 0 || len(list.AllowedMentions.Users) != 0 {
+		t.Errorf("list allows mentions: %+v", list.AllowedMentions)
+	}
+
+	msg := waitMessage(t, h, "reminder: @everyone check backups")
+
+	if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 {
+		t.Fatalf("reminder allows mentions: %+v", msg.AllowedMentions)
+	}
+
+	if users := msg.AllowedMentions.Users; len(users) != 1 || users[0] != discordanttest.UserID {
+		t.Errorf("reminder allows user mentions %v", users)
+	}
+}
+
+func TestRemindDM(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddReminderCommands(discordant.NewStoreReminderStore(discordant.NewMemoryStore()))
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind dm in 1 second renew cert")
+
+	msg := waitMessage(t, h, "Reminder: renew cert")
+
+	if dm := h.DMChannel(discordanttest.UserID); dm == "" || msg.ChannelID != dm {
+		t.Errorf("reminder is sent to %s, not to DM channel %q", msg.ChannelID, dm)
+	}
+
+	if msg.AllowedMentions == nil || len(msg.AllowedMentions.Users) != 0 {
+		t.Errorf("DM reminder allows mentions: %+v", msg.AllowedMentions)
+	}
+}
+
+func TestRemindRetriesFailedDelivery(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	store := discordant.NewStoreReminderStore(discordant.NewMemoryStore())
+	h.Bot.AddReminderCommands(store, discordant.ReminderRetryInterval(50*time.Millisecond))
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind in 1s check backups")
+	h.Session.SetErr(errors.New("discord is down"))
+
+	time.Sleep(1200 * time.Millisecond)
+
+	if reminders, err := store.Reminders(); err != nil || len(reminders) != 1 {
+		t.Fatalf("failed reminder is not kept: %v, %v", reminders, err)
+	}
+
+	h.Session.SetErr(nil)
+
+	waitMessage(t, h, "reminder: check backups")
+	waitNoReminders(t, store)
+}
+
+func TestRemindDropsUndeliverable(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		options []discordant.ReminderOption
+	}{
+		{
+			name: "unknown channel",
+			err: &discordgo.RESTError{
+				Response: &http.Response{StatusCode: http.StatusNotFound},
+				Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeUnknownChannel},
+			},
+		},
+		{
+			name:    "attempts exhausted",
+			err:     &discordgo.RESTError{Response: &http.Response{StatusCode: http.StatusTooManyRequests}},
+			options: []discordant.ReminderOption{discordant.ReminderMaxAttempts(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := discordanttest.New(t, nil)
+			store := discordant.NewStoreReminderStore(discordant.NewMemoryStore())
+			options := append([]discordant.ReminderOption{discordant.ReminderRetryInterval(10 * time.Millisecond)}, tt.options...)
+			h.Bot.AddReminderCommands(store, options...)
+
+			h.Exec(discordanttest.GeneralChannelID, "!remind in 1s check backups")
+			h.Session.SetErr(tt.err)
+
+			waitNoReminders(t, store)
+		})
+	}
+}
+
+// waitNoReminders waits until the store has no reminders.
+func waitNoReminders(t *testing.T, store discordant.ReminderStore) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+
+	for {
+		reminders, err := store.Reminders()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(reminders) == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("reminders are not deleted: %v", reminders)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRemindRejectsOverflowingDuration(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddReminderCommands(nil)
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind in 9223372036854775807 weeks overflow")
+	h.AssertMessageContains("invalid duration")
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind in 400 days too late")
+	h.AssertMessageContains("can't be longer")
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind list")
+	h.AssertMessage("You have no reminders")
+}
+
+func TestRemindListCancel(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	store := discordant.NewStoreReminderStore(discordant.NewMemoryStore())
+	h.Bot.AddReminderCommands(store)
+
+	h.Exec(discordanttest.GeneralChannelID, "!remind in 1h check backups")
+	h.Exec(discordanttest.GeneralChannelID, "!remind dm in 2h renew cert")
+
+	reminders, err := store.Reminders()
+	if err != nil || len(reminders) != 2 {
+		t.Fatalf("stored reminders %v, %v", reminders, err)
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!remind list")
+	h.AssertMessageContains("check backups\n")
+	h.AssertMessageContains("renew cert (dm)")
+
+	// Reminders of other users are not listed and can't be canceled.
+	h.Reset()
+	h.Dispatch(discordanttest.Request{
+		Content: "!remind cancel " + reminders[0].ID,
+		Author:  &discordgo.User{ID: "700000000000000009", Username: "other"},
+	})
+	h.AssertMessageContains("not found")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!remind cancel "+reminders[0].ID)
+	h.AssertSuccess()
+
+	if reminders, err := store.Reminders(); err != nil || len(reminders) != 1 {
+		t.Errorf("reminders after cancel %v, %v", reminders, err)
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!remind tomorrow")
+	h.AssertMessageContains("usage: remind")
+}
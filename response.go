@@ -0,0 +1,213 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Response message formats used when Success or Fail carries a message.
+const (
+	ResponseMessageFormatFail    = "```fail: %s```"
+	ResponseMessageFormatSuccess = "```success: %s```"
+)
+
+// ResponseKind describes the way the response is delivered to the channel.
+type ResponseKind int
+
+// Response kinds.
+const (
+	// ResponseKindText sends response as plain text message.
+	ResponseKindText ResponseKind = iota
+
+	// ResponseKindEmbed sends response as embed with title and color.
+	ResponseKindEmbed
+
+	// ResponseKindReaction adds reaction to the request message.
+	ResponseKindReaction
+)
+
+// Response is a template for Success and Fail responses. It can be set for the
+// whole bot with SetSuccessResponse and SetFailResponse options or for a single
+// command with MiddlewareSuccessResponse and MiddlewareFailResponse.
+type Response struct {
+	Kind ResponseKind
+
+	// Text is a plain text message for ResponseKindText or an embed title
+	// for ResponseKindEmbed.
+	Text string
+
+	// Format is a layout with a single %s verb that is used for ResponseKindText
+	// when response carries a message. If empty the message is sent as is.
+	// Literal percent sign is written as %%.
+	Format string
+
+	// Color is an embed color for ResponseKindEmbed.
+	Color int
+
+	// Emoji is a reaction for ResponseKindReaction.
+	Emoji string
+}
+
+// Default responses.
+var (
+	DefaultSuccessResponse = Response{
+		Kind:   ResponseKindText,
+		Text:   ResponseMessageSuccess,
+		Format: ResponseMessageFormatSuccess,
+	}
+
+	DefaultFailResponse = Response{
+		Kind:   ResponseKindText,
+		Text:   ResponseMessageFail,
+		Format: ResponseMessageFormatFail,
+	}
+)
+
+// Validate returns ErrInvalidResponseFormat if Format of text response is set
+// and doesn't have exactly one %s verb. Templates set with SetSuccessResponse
+// and SetFailResponse are validated by New, command templates when they are
+// sent.
+func (r Response) Validate() error {
+	if r.Kind != ResponseKindText || r.Format == "" {
+		return nil
+	}
+
+	verbs := 0
+
+	for i := 0; i < len(r.Format); i++ {
+		if r.Format[i] != '%' {
+			continue
+		}
+
+		i++
+
+		switch {
+		case i < len(r.Format) && r.Format[i] == '%':
+			continue
+		case i < len(r.Format) && r.Format[i] == 's':
+			verbs++
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidResponseFormat, r.Format)
+		}
+	}
+
+	if verbs != 1 {
+		return fmt.Errorf("%w: %q", ErrInvalidResponseFormat, r.Format)
+	}
+
+	return nil
+}
+
+// TextResponse creates plain text response template. Format is checked by
+// Response.Validate.
+func TextResponse(text, format string) Response {
+	return Response{Kind: ResponseKindText, Text: text, Format: format}
+}
+
+// EmbedResponse creates embed response template.
+func EmbedResponse(title string, color int) Response {
+	return Response{Kind: ResponseKindEmbed, Text: title, Color: color}
+}
+
+// ReactionResponse creates reaction response template.
+func ReactionResponse(emoji string) Response {
+	return Response{Kind: ResponseKindReaction, Emoji: emoji}
+}
+
+// MiddlewareSuccessResponse sets success response template to command.
+func MiddlewareSuccessResponse(response Response) CommandOption {
+	return func(c *Command) {
+		c.success = &response
+	}
+}
+
+// MiddlewareFailResponse sets fail response template to command.
+func MiddlewareFailResponse(response Response) CommandOption {
+	return func(c *Command) {
+		c.fail = &response
+	}
+}
+
+// SuccessWith sends a success response message with the given text.
+func (c *context) SuccessWith(msg string) error {
+	return c.respond(c.successResponse(), msg)
+}
+
+// FailWith sends a fail response with the safe message or embed of
+// UserError. Other errors may contain internal details, so they are logged
+// with error level and the default fail response is sent. FailWith is the
+// only place where such errors are logged, DefaultErrorHandler relies on it.
+func (c *context) FailWith(err error) error {
+	if err == nil {
+		return c.Fail()
+	}
+
+	var userErr *UserError
+	if !errors.As(err, &userErr) {
+		c.Logger().Errorf("discordant action: %s", err)
+
+		return c.Fail()
+	}
+
+	if userErr.Embed != nil {
+		return c.Embed(userErr.Embed)
+	}
+
+	return c.respond(c.failResponse(), userErr.Message)
+}
+
+func (c *context) successResponse() Response {
+	if c.command != nil && c.command.success != nil {
+		return *c.command.success
+	}
+
+	return c.discordant.successResponse
+}
+
+func (c *context) failResponse() Response {
+	if c.command != nil && c.command.fail != nil {
+		return *c.command.fail
+	}
+
+	return c.discordant.failResponse
+}
+
+// respond sends response by template. Message is optional.
+func (c *context) respond(response Response, msg string) error {
+	switch response.Kind {
+	case ResponseKindText:
+		switch {
+		case msg == "":
+			return c.Send(response.Text)
+		case response.Format != "":
+			if err := response.Validate(); err != nil {
+				return err
+			}
+
+			return c.Send(fmt.Sprintf(response.Format, msg))
+		default:
+			return c.Send(msg)
+		}
+	case ResponseKindEmbed:
+		return c.Embed(&discordgo.MessageEmbed{
+			Title:       response.Text,
+			Description: msg,
+			Color:       response.Color,
+		})
+	case ResponseKindReaction:
+		err := c.discordant.addReaction(c.trace, c.request.ChannelID, c.request.ID, response.Emoji)
+		if err != nil {
+			return fmt.Errorf("discordant react: %w", err)
+		}
+
+		if msg == "" {
+			return nil
+		}
+
+		return c.Send(msg)
+	default:
+		return ErrInvalidResponseMessageType
+	}
+}
@@ -0,0 +1,152 @@
+package discordant_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestFailWith(t *testing.T) {
+	var logger lineLogger
+
+	h := discordanttest.New(t, nil, discordant.SetLogger(&logger))
+	h.Bot.ALL("internal", func(ctx discordant.Context) error {
+		return ctx.FailWith(errors.New("dial postgres://admin:secret@db"))
+	})
+	h.Bot.ALL("user", func(ctx discordant.Context) error {
+		return ctx.FailWith(discordant.WrapUserError("try again later", errors.New("timeout")))
+	})
+
+	h.Bot.ALL("returned", func(discordant.Context) error {
+		return errors.New("dial postgres://admin:secret@db")
+	})
+
+	for _, command := range []string{"!internal", "!returned"} {
+		logger.lines = nil
+
+		h.Reset()
+		h.Exec(discordanttest.GeneralChannelID, command)
+		h.AssertFail()
+
+		if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "dial postgres") {
+			t.Errorf("%s error is logged as %q, want one line", command, logger.lines)
+		}
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!internal")
+
+	for _, msg := range h.Messages() {
+		if strings.Contains(msg.Content, "secret") {
+			t.Errorf("internal error is sent: %q", msg.Content)
+		}
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!user")
+	h.AssertMessageContains("try again later")
+
+	if msg := h.LastMessage(); strings.Contains(msg.Content, "timeout") {
+		t.Errorf("wrapped error is sent: %q", msg.Content)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var (
+		logger lineLogger
+		errs   []error
+	)
+
+	h := discordanttest.New(t, nil, discordant.SetLogger(&logger), discordant.SetErrorHandler(
+		func(ctx discordant.Context, err error) {
+			errs = append(errs, err)
+			_ = ctx.Send("custom")
+		},
+	))
+	h.Bot.ALL("broken", func(discordant.Context) error { return errors.New("broken") })
+
+	h.Exec(discordanttest.GeneralChannelID, "!broken")
+
+	h.AssertMessage("custom")
+	h.AssertMessagesCount(1)
+
+	if len(errs) != 1 || errs[0].Error() != "broken" {
+		t.Errorf("handled errors: %v", errs)
+	}
+
+	if len(logger.lines) != 0 {
+		t.Errorf("error is logged by default handler: %q", logger.lines)
+	}
+}
+
+func TestResponseTemplates(t *testing.T) {
+	h := discordanttest.New(t, nil,
+		discordant.SetSuccessResponse(discordant.ReactionResponse("👍")),
+		discordant.SetFailResponse(discordant.EmbedResponse("Failed", discordant.ColorRed)),
+	)
+	h.Bot.ALL("ok", func(ctx discordant.Context) error { return ctx.Success() })
+	h.Bot.ALL("done", func(ctx discordant.Context) error { return ctx.SuccessWith("done") })
+	h.Bot.ALL("fail", func(ctx discordant.Context) error {
+		return ctx.FailWith(discordant.NewUserError("no access"))
+	})
+	h.Bot.ALL("text", func(ctx discordant.Context) error { return ctx.SuccessWith("saved") },
+		discordant.MiddlewareSuccessResponse(discordant.TextResponse("ok", "100%% %s")))
+
+	h.Exec(discordanttest.GeneralChannelID, "!ok")
+	h.AssertReaction("👍")
+	h.AssertNoMessages()
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!done")
+	h.AssertReaction("👍")
+	h.AssertMessage("done")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!fail")
+	h.AssertEmbed("Failed")
+
+	if embed := h.LastMessage().Embeds[0]; embed.Description != "no access" || embed.Color != discordant.ColorRed {
+		t.Errorf("fail embed: %+v", embed)
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!text")
+	h.AssertMessage("100% saved")
+}
+
+func TestResponseFormat(t *testing.T) {
+	for _, format := range []string{"", "%s", "done: %s", "100%% %s"} {
+		if err := discordant.TextResponse("ok", format).Validate(); err != nil {
+			t.Errorf("Validate(%q): %s", format, err)
+		}
+	}
+
+	for _, format := range []string{"done", "%d", "%s %s", "%v", "100% %s", "%"} {
+		if err := discordant.TextResponse("ok", format).Validate(); !errors.Is(err, discordant.ErrInvalidResponseFormat) {
+			t.Errorf("Validate(%q) = %v, want %v", format, err, discordant.ErrInvalidResponseFormat)
+		}
+	}
+
+	_, err := discordant.New(discordanttest.NewConfig(),
+		discordant.SetTransport(discordanttest.NewSession()), discordant.SetID(discordanttest.BotID),
+		discordant.SetFailResponse(discordant.TextResponse("fail", "fail: %d")))
+	if !errors.Is(err, discordant.ErrInvalidResponseFormat) {
+		t.Errorf("New with invalid fail format: %v", err)
+	}
+
+	h := discordanttest.New(t, nil)
+	h.Bot.ALL("save", func(ctx discordant.Context) error { return ctx.SuccessWith("saved") },
+		discordant.MiddlewareSuccessResponse(discordant.TextResponse("ok", "saved %d")))
+
+	h.Exec(discordanttest.GeneralChannelID, "!save")
+	h.AssertFail()
+
+	for _, msg := range h.Messages() {
+		if strings.Contains(msg.Content, "%!") {
+			t.Errorf("malformed message is sent: %q", msg.Content)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package discordant
+
+import (
+	ctx "context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant/internal/cron"
+)
+
+// CommandJobs is the name of the jobs admin command.
+const CommandJobs = "jobs"
+
+// JobFunc defines a function to run scheduled job.
+type JobFunc func(JobContext) error
+
+// JobContext is the context of the current job run.
+type JobContext interface {
+	Job() string
+	Discordant() *Discordant
+	Logger() Logger
+	StdContext() ctx.Context
+
+	// Send sends message to the channel resolved by name from Config.Channels.
+	// Message longer than Discord limit is attached as file.
+	Send(channelName, msg string, params ...string) error
+
+	// Embed sends embed to the channel resolved by name from Config.Channels.
+	Embed(channelName string, embed *discordgo.MessageEmbed) error
+}
+
+// JobStatus describes scheduled job.
+type JobStatus struct {
+	Name         string
+	Schedule     string
+	Paused       bool
+	Running      bool
+	Next         time.Time
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+	Runs         int
+	Skipped      int
+}
+
+// Schedule registers job that runs by cron expression such as `0 9 * * mon-fri`
+// or descriptor such as `@daily` or `@every 10m`. Jobs run after Run until
+// Close. Run of a job is skipped if the previous run is still going.
+func (d *Discordant) Schedule(name, spec string, fn JobFunc) error {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("discordant scheduler: %s: %w: %w", name, ErrInvalidSchedule, err)
+	}
+
+	return d.scheduler.add(&job{name: name, spec: spec, schedule: schedule, fn: fn})
+}
+
+// Every registers job that runs with the interval.
+func (d *Discordant) Every(name string, interval time.Duration, fn JobFunc) error {
+	if interval <= 0 {
+		return fmt.Errorf("discordant scheduler: %s: %w: interval must be positive", name, ErrInvalidSchedule)
+	}
+
+	return d.scheduler.add(&job{name: name, spec: "@every " + interval.String(), schedule: cron.Every(interval), fn: fn})
+}
+
+// Jobs returns status of scheduled jobs sorted by name.
+func (d *Discordant) Jobs() []JobStatus {
+	return d.scheduler.status()
+}
+
+// PauseJob pauses scheduled runs of the job. Job still can be triggered.
+func (d *Discordant) PauseJob(name string) error {
+	return d.scheduler.setPaused(name, true)
+}
+
+// ResumeJob resumes scheduled runs of the job.
+func (d *Discordant) ResumeJob(name string) error {
+	return d.scheduler.setPaused(name, false)
+}
+
+// TriggerJob runs the job out of schedule in background. It returns
+// ErrJobNotStarted if the previous run is still going or Discordant is closed.
+func (d *Discordant) TriggerJob(name string) error {
+	return d.scheduler.trigger(name)
+}
+
+// AddJobCommands adds `jobs` route handler to admin channel. `jobs` lists
+// scheduled jobs, `jobs pause <job>`, `jobs resume <job>` and
+// `jobs trigger <job>` manage them.
+func (d *Discordant) AddJobCommands(options ...CommandOption) {
+	d.ADMIN(CommandJobs, d.jobsHandler, append([]CommandOption{
+		MiddlewareDescription("lists, pauses, resumes and triggers scheduled jobs"),
+	}, options...)...)
+}
+
+func (d *Discordant) jobsHandler(ctx Context) error {
+	args, err := ctx.QuerySlice()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return ctx.Send(formatJobs(d.Jobs()))
+	}
+
+	if len(args) != 2 {
+		return NewUserError("usage: jobs [pause|resume|trigger <job>]")
+	}
+
+	action, name := args[0], args[1]
+
+	switch action {
+	case "pause":
+		err = d.PauseJob(name)
+	case "resume":
+		err = d.ResumeJob(name)
+	case "trigger":
+		err = d.TriggerJob(name)
+	default:
+		return NewUserError(fmt.Sprintf("unknown action %q", action))
+	}
+
+	if err != nil {
+		return WrapUserError(fmt.Sprintf("can't %s job %q", action, name), err)
+	}
+
+	return ctx.Success()
+}
+
+func formatJobs(jobs []JobStatus) string {
+	if len(jobs) == 0 {
+		return "```no scheduled jobs```"
+	}
+
+	var buf strings.Builder
+
+	buf.WriteString("```")
+
+	for _, job := range jobs {
+		state := "active"
+
+		switch {
+		case job.Running:
+			state = "running"
+		case job.Paused:
+			state = "paused"
+		}
+
+		fmt.Fprintf(&buf, "%s [%s] %s: runs %d, skipped %d", job.Name, job.Schedule, state, job.Runs, job.Skipped)
+
+		if !job.Next.IsZero() && !job.Paused {
+			fmt.Fprintf(&buf, ", next %s", job.Next.Format(time.RFC3339))
+		}
+
+		if !job.LastRun.IsZero() {
+			fmt.Fprintf(&buf, ", last %s (%s)", job.LastRun.Format(time.RFC3339), job.LastDuration.Round(time.Millisecond))
+		}
+
+		if job.LastError != "" {
+			fmt.Fprintf(&buf, ", error: %s", job.LastError)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("```")
+
+	return buf.String()
+}
+
+// scheduler runs jobs by their schedules.
+type scheduler struct {
+	discordant *Discordant
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	stopped bool
+	stdctx  ctx.Context
+	cancel  ctx.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	fn       JobFunc
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+func newScheduler(d *Discordant) *scheduler {
+	stdctx, cancel := ctx.WithCancel(ctx.Background())
+
+	return &scheduler{
+		discordant: d,
+		jobs:       make(map[string]*job),
+		stdctx:     stdctx,
+		cancel:     cancel,
+	}
+}
+
+func (s *scheduler) add(j *job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[j.name]; ok {
+		return fmt.Errorf("discordant scheduler: %w: %s", ErrDuplicateJob, j.name)
+	}
+
+	j.status = JobStatus{Name: j.name, Schedule: j.spec}
+	s.jobs[j.name] = j
+
+	if s.started {
+		s.wg.Add(1)
+
+		go s.loop(j)
+	}
+
+	return nil
+}
+
+// start starts scheduling of registered jobs.
+func (s *scheduler) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started || s.stopped {
+		return
+	}
+
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+
+		go s.loop(j)
+	}
+}
+
+// running reports whether the scheduler is started and not stopped.
+func (s *scheduler) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// stop stops scheduling, cancels context of running jobs and waits for them.
+func (s *scheduler) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.started = false
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.discordant.logger.Warningf("discordant scheduler: job %s has no next run", j.name)
+
+			return
+		}
+
+		j.mu.Lock()
+		j.status.Next = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-s.stdctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+
+		j.mu.Lock()
+		paused := j.status.Paused
+		j.mu.Unlock()
+
+		if paused {
+			continue
+		}
+
+		if !s.run(j) && s.stdctx.Err() == nil {
+			s.discordant.logger.Warningf("discordant scheduler: job %s skipped: previous run is still going", j.name)
+		}
+	}
+}
+
+// run starts the job in background. It returns false if the previous run is
+// still going or the scheduler is stopped.
+func (s *scheduler) run(j *job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return false
+	}
+
+	j.mu.Lock()
+
+	if j.status.Running {
+		j.status.Skipped++
+		j.mu.Unlock()
+
+		return false
+	}
+
+	j.status.Running = true
+	j.mu.Unlock()
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		start := time.Now()
+		err := s.exec(j)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.status.Running = false
+		j.status.Runs++
+		j.status.LastRun = start
+		j.status.LastDuration = time.Since(start)
+		j.status.LastError = ""
+
+		if err != nil {
+			j.status.LastError = err.Error()
+		}
+	}()
+
+	return true
+}
+
+// exec runs the job function with tracing.
+func (s *scheduler) exec(j *job) error {
+	d := s.discordant
+
+	trace, span := d.tracer.Start(s.stdctx, SpanJob, Attr(AttrJob, j.name))
+
+	jc := jobContext{
+		job:        j.name,
+		discordant: d,
+		logger:     WithFields(d.logger, Fields{FieldJob: j.name}),
+		trace:      trace,
+	}
+
+	err := call(j.fn, &jc)
+	if err != nil {
+		jc.logger.Errorf("discordant scheduler: job %s: %s", j.name, err)
+	}
+
+	endSpan(span, err)
+
+	return err
+}
+
+// call calls the job function and turns panic into error, so that broken job
+// doesn't crash the bot.
+func call(fn JobFunc, jc *jobContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jc.logger.Debugf("discordant scheduler: job %s panic stack: %s", jc.job, debug.Stack())
+
+			err = fmt.Errorf("%w: %v", ErrJobPanic, r)
+		}
+	}()
+
+	return fn(jc)
+}
+
+func (s *scheduler) find(name string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("discordant scheduler: %w: %s", ErrUnknownJob, name)
+	}
+
+	return j, nil
+}
+
+func (s *scheduler) setPaused(name string, paused bool) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.status.Paused = paused
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (s *scheduler) trigger(name string) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+
+	if !s.run(j) {
+		return fmt.Errorf("discordant scheduler: %w: %s", ErrJobNotStarted, name)
+	}
+
+	return nil
+}
+
+func (s *scheduler) status() []JobStatus {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+
+	for _, j := range jobs {
+		j.mu.Lock()
+		statuses = append(statuses, j.status)
+		j.mu.Unlock()
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// jobContext implements JobContext.
+type jobContext struct {
+	job        string
+	discordant *Discordant
+	logger     Logger
+	trace      ctx.Context
+}
+
+// Job returns job name.
+func (c *jobContext) Job() string {
+	return c.job
+}
+
+// Discordant returns Discordant instance.
+func (c *jobContext) Discordant() *Discordant {
+	return c.discordant
+}
+
+// Logger returns logger with job field.
+func (c *jobContext) Logger() Logger {
+	return c.logger
+}
+
+// StdContext returns context.Context that is canceled on Close and carries
+// the job span.
+func (c *jobContext) StdContext() ctx.Context {
+	return c.trace
+}
+
+// Send sends message to the channel resolved by name.
+func (c *jobContext) Send(channelName, msg string, params ...string) error {
+	channelID, err := c.discordant.channelID(channelName)
+	if err != nil {
+		return fmt.Errorf("discordant job send: %w", err)
+	}
+
+	if err := c.discordant.send(c.trace, channelID, msg, params...); err != nil {
+		return fmt.Errorf("discordant job send: %w", err)
+	}
+
+	return nil
+}
+
+// Embed sends embed to the channel resolved by name.
+func (c *jobContext) Embed(channelName string, embed *discordgo.MessageEmbed) error {
+	channelID, err := c.discordant.channelID(channelName)
+	if err != nil {
+		return fmt.Errorf("discordant job send: %w", err)
+	}
+
+	if _, err := c.discordant.sendEmbed(c.trace, channelID, embed); err != nil {
+		return fmt.Errorf("discordant job send: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package discordant_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+// waitJobRuns waits until the job has runs and returns its status.
+func waitJobRuns(t *testing.T, h *discordanttest.Harness, name string, runs int) discordant.JobStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		for _, job := range h.Bot.Jobs() {
+			if job.Name == name && job.Runs >= runs && !job.Running {
+				return job
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s has less than %d runs", name, runs)
+
+	return discordant.JobStatus{}
+}
+
+func TestJobPanic(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if err := h.Bot.Every("panic", time.Hour, func(discordant.JobContext) error {
+		panic("broken job")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Bot.TriggerJob("panic"); err != nil {
+		t.Fatalf("TriggerJob: %s", err)
+	}
+
+	job := waitJobRuns(t, h, "panic", 1)
+
+	if !strings.Contains(job.LastError, discordant.ErrJobPanic.Error()) || !strings.Contains(job.LastError, "broken job") {
+		t.Errorf("last error %q", job.LastError)
+	}
+
+	// Job can run again after panic.
+	if err := h.Bot.TriggerJob("panic"); err != nil {
+		t.Fatalf("second TriggerJob: %s", err)
+	}
+
+	waitJobRuns(t, h, "panic", 2)
+}
+
+func TestJobError(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if err := h.Bot.Every("fail", time.Hour, func(discordant.JobContext) error {
+		return errors.New("failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Bot.TriggerJob("fail"); err != nil {
+		t.Fatalf("TriggerJob: %s", err)
+	}
+
+	if job := waitJobRuns(t, h, "fail", 1); job.LastError != "failed" {
+		t.Errorf("last error %q, want failed", job.LastError)
+	}
+}
@@ -0,0 +1,466 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// CommandConfig is the name of the settings admin command.
+const CommandConfig = "config"
+
+// GuildArgument prefixes the first argument of admin commands that selects
+// the guild to configure, for example `config guild:<id> set <key> <value>`.
+// Admin channel belongs to one guild, so other guilds are configured from it
+// with the argument.
+const GuildArgument = "guild:"
+
+// settingsGlobal is the store scope of settings outside of guilds such as
+// direct messages.
+const settingsGlobal = "_"
+
+// SettingType is the type of setting value.
+type SettingType int
+
+// Setting types.
+const (
+	SettingString SettingType = iota
+	SettingInt
+	SettingBool
+	SettingDuration
+)
+
+// String returns name of the setting type.
+func (t SettingType) String() string {
+	switch t {
+	case SettingString:
+		return "string"
+	case SettingInt:
+		return "int"
+	case SettingBool:
+		return "bool"
+	case SettingDuration:
+		return "duration"
+	default:
+		return "unknown"
+	}
+}
+
+// Setting declares per-guild setting. Default must have Go type of the
+// setting type: string, int, bool or time.Duration. Nil Default means zero
+// value. Validate is called with the parsed value before it is stored.
+type Setting struct {
+	Key         string
+	Type        SettingType
+	Default     any
+	Description string
+	Validate    func(value any) error
+}
+
+// AddSetting declares setting, so that it can be read by handlers and changed
+// with `config` admin command. Features should prefix keys with their name
+// such as `welcome.channel`.
+func (d *Discordant) AddSetting(setting Setting) error {
+	if setting.Key == "" || strings.ContainsAny(setting.Key, " \t\n/") {
+		return fmt.Errorf("discordant settings: %w: invalid key %q", ErrInvalidSetting, setting.Key)
+	}
+
+	if setting.Default == nil {
+		setting.Default = setting.Type.zero()
+	}
+
+	if err := setting.Type.check(setting.Default); err != nil {
+		return fmt.Errorf("discordant settings: %s default: %w", setting.Key, err)
+	}
+
+	return d.settings.add(setting)
+}
+
+// Settings returns settings of the guild. Empty guildID selects settings
+// used outside of guilds.
+func (d *Discordant) Settings(guildID string) Settings {
+	if guildID == "" {
+		guildID = settingsGlobal
+	}
+
+	return Settings{discordant: d, guildID: guildID}
+}
+
+// AddSettingsCommands adds `config` route handler to admin channel:
+//
+//	config list                lists settings of the guild
+//	config get <key>           shows setting
+//	config set <key> <value>   changes setting
+//	config reset <key>         restores default value
+//
+// Commands change settings of the admin channel guild unless another guild
+// is selected with GuildArgument: `config guild:<id> list`.
+func (d *Discordant) AddSettingsCommands(options ...CommandOption) {
+	d.ADMIN(CommandConfig, d.settingsHandler, append([]CommandOption{
+		MiddlewareDescription("lists, shows, changes and resets guild settings"),
+	}, options...)...)
+}
+
+func (d *Discordant) settingsHandler(ctx Context) error {
+	guildID, query, err := cutGuild(ctx)
+	if err != nil {
+		return err
+	}
+
+	action, rest, _ := strings.Cut(query, DefaultCommandDelimiter)
+	key, value, _ := strings.Cut(strings.TrimSpace(rest), DefaultCommandDelimiter)
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+	settings := d.Settings(guildID)
+
+	switch {
+	case action == "" || (action == "list" && key == ""):
+		return d.sendSettings(ctx, settings, d.settings.all())
+	case action == "get" && key != "" && value == "":
+		setting, err := d.settings.find(key)
+		if err != nil {
+			return WrapUserError(fmt.Sprintf("setting %q not found", key), err)
+		}
+
+		return d.sendSettings(ctx, settings, []Setting{setting})
+	case action == "set" && key != "" && value != "":
+		err := settings.Set(key, value)
+		if errors.Is(err, ErrInvalidSetting) {
+			// Validation errors are safe to show and tell what is wrong.
+			return WrapUserError(fmt.Sprintf("can't set %q: %s", key, err), err)
+		}
+
+		if err != nil {
+			return WrapUserError(fmt.Sprintf("can't set %q", key), err)
+		}
+
+		return ctx.Success()
+	case action == "reset" && key != "" && value == "":
+		if err := settings.Reset(key); err != nil {
+			return WrapUserError(fmt.Sprintf("can't reset %q", key), err)
+		}
+
+		return ctx.Success()
+	default:
+		return NewUserError("usage: config [guild:<id>] [list], config [guild:<id>] get <key>, " +
+			"config [guild:<id>] set <key> <value>, config [guild:<id>] reset <key>")
+	}
+}
+
+// cutGuild returns guild selected with GuildArgument and the rest of the
+// query. Without the argument it returns guild of the request.
+func cutGuild(ctx Context) (string, string, error) {
+	query := ctx.QueryString()
+
+	first, rest := query, ""
+	if i := strings.IndexFunc(query, unicode.IsSpace); i >= 0 {
+		first, rest = query[:i], strings.TrimSpace(query[i:])
+	}
+
+	guildID, ok := strings.CutPrefix(first, GuildArgument)
+	if !ok {
+		return ctx.Request().GuildID, query, nil
+	}
+
+	if !IsSnowflake(guildID) {
+		return "", "", NewUserError(fmt.Sprintf("invalid guild id %q", guildID))
+	}
+
+	return guildID, rest, nil
+}
+
+func (d *Discordant) sendSettings(ctx Context, settings Settings, list []Setting) error {
+	if len(list) == 0 {
+		return ctx.Send("```no settings```")
+	}
+
+	var buf strings.Builder
+
+	buf.WriteString("```")
+
+	for _, setting := range list {
+		value, err := settings.Get(setting.Key)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&buf, "%s (%s) = %s", setting.Key, setting.Type, formatSetting(value))
+
+		if custom, err := settings.custom(setting.Key); err == nil && !custom {
+			buf.WriteString(" [default]")
+		}
+
+		if setting.Description != "" {
+			fmt.Fprintf(&buf, ": %s", setting.Description)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("```")
+
+	return ctx.Send(buf.String())
+}
+
+// Settings is a view of settings of one guild. Values are kept in
+// Discordant Store under `settings/<guild>/` prefix.
+type Settings struct {
+	discordant *Discordant
+	guildID    string
+}
+
+// Get returns setting value or its default. It returns ErrUnknownSetting if
+// the setting is not declared.
+func (s Settings) Get(key string) (any, error) {
+	setting, err := s.discordant.settings.find(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.discordant.store.Get(s.key(key))
+	if errors.Is(err, ErrKeyNotFound) {
+		return setting.Default, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("discordant settings: %w", err)
+	}
+
+	value, err := setting.Type.parse(string(data))
+	if err != nil {
+		s.discordant.logger.Warningf("discordant settings: %s of guild %s: %s, default is used", key, s.guildID, err)
+
+		return setting.Default, nil
+	}
+
+	return value, nil
+}
+
+// String returns value of string setting.
+func (s Settings) String(key string) (string, error) {
+	return getSetting[string](s, key, SettingString)
+}
+
+// Int returns value of int setting.
+func (s Settings) Int(key string) (int, error) {
+	return getSetting[int](s, key, SettingInt)
+}
+
+// Bool returns value of bool setting.
+func (s Settings) Bool(key string) (bool, error) {
+	return getSetting[bool](s, key, SettingBool)
+}
+
+// Duration returns value of duration setting.
+func (s Settings) Duration(key string) (time.Duration, error) {
+	return getSetting[time.Duration](s, key, SettingDuration)
+}
+
+// Set parses, validates and stores setting value.
+func (s Settings) Set(key, raw string) error {
+	setting, err := s.discordant.settings.find(key)
+	if err != nil {
+		return err
+	}
+
+	value, err := setting.Type.parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if setting.Validate != nil {
+		if err := setting.Validate(value); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSetting, err)
+		}
+	}
+
+	if err := s.discordant.store.Set(s.key(key), []byte(formatSetting(value)), 0); err != nil {
+		return fmt.Errorf("discordant settings: %w", err)
+	}
+
+	return nil
+}
+
+// Reset restores default setting value.
+func (s Settings) Reset(key string) error {
+	if _, err := s.discordant.settings.find(key); err != nil {
+		return err
+	}
+
+	if err := s.discordant.store.Delete(s.key(key)); err != nil {
+		return fmt.Errorf("discordant settings: %w", err)
+	}
+
+	return nil
+}
+
+// custom reports whether the guild has its own value of the setting.
+func (s Settings) custom(key string) (bool, error) {
+	_, err := s.discordant.store.Get(s.key(key))
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (s Settings) key(key string) string {
+	return "settings/" + s.guildID + "/" + key
+}
+
+func getSetting[T any](s Settings, key string, typ SettingType) (T, error) {
+	var zero T
+
+	setting, err := s.discordant.settings.find(key)
+	if err != nil {
+		return zero, err
+	}
+
+	if setting.Type != typ {
+		return zero, fmt.Errorf("discordant settings: %w: %s is %s, not %s", ErrInvalidSetting, key, setting.Type, typ)
+	}
+
+	value, err := s.Get(key)
+	if err != nil {
+		return zero, err
+	}
+
+	return value.(T), nil
+}
+
+func (t SettingType) zero() any {
+	switch t {
+	case SettingInt:
+		return 0
+	case SettingBool:
+		return false
+	case SettingDuration:
+		return time.Duration(0)
+	default:
+		return ""
+	}
+}
+
+// check checks that Go type of the value matches the setting type.
+func (t SettingType) check(value any) error {
+	var ok bool
+
+	switch t {
+	case SettingString:
+		_, ok = value.(string)
+	case SettingInt:
+		_, ok = value.(int)
+	case SettingBool:
+		_, ok = value.(bool)
+	case SettingDuration:
+		_, ok = value.(time.Duration)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %T is not %s", ErrInvalidSetting, value, t)
+	}
+
+	return nil
+}
+
+func (t SettingType) parse(raw string) (any, error) {
+	switch t {
+	case SettingString:
+		return raw, nil
+	case SettingInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not int", ErrInvalidSetting, raw)
+		}
+
+		return n, nil
+	case SettingBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not bool", ErrInvalidSetting, raw)
+		}
+
+		return b, nil
+	case SettingDuration:
+		d, err := ParseDuration(raw)
+		if err != nil {
+			// Go syntax covers zero, fractions and units such as ms. It is
+			// also the format values are stored in.
+			var goErr error
+			if d, goErr = time.ParseDuration(raw); goErr != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidSetting, err)
+			}
+		}
+
+		return d, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type %d", ErrInvalidSetting, t)
+	}
+}
+
+// formatSetting formats value so that it can be parsed back.
+func formatSetting(value any) string {
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+
+	return fmt.Sprint(value)
+}
+
+// settingsRegistry is a thread-safe storage of declared settings.
+type settingsRegistry struct {
+	mu       sync.RWMutex
+	settings map[string]Setting
+}
+
+func newSettingsRegistry() *settingsRegistry {
+	return &settingsRegistry{settings: make(map[string]Setting)}
+}
+
+func (r *settingsRegistry) add(setting Setting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.settings[setting.Key]; ok {
+		return fmt.Errorf("discordant settings: %w: %s", ErrDuplicateSetting, setting.Key)
+	}
+
+	r.settings[setting.Key] = setting
+
+	return nil
+}
+
+func (r *settingsRegistry) find(key string) (Setting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	setting, ok := r.settings[key]
+	if !ok {
+		return Setting{}, fmt.Errorf("discordant settings: %w: %s", ErrUnknownSetting, key)
+	}
+
+	return setting, nil
+}
+
+// all returns settings sorted by key.
+func (r *settingsRegistry) all() []Setting {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := make([]Setting, 0, len(r.settings))
+
+	for _, setting := range r.settings {
+		settings = append(settings, setting)
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+
+	return settings
+}
@@ -0,0 +1,186 @@
+package discordant_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func addSettings(t *testing.T, h *discordanttest.Harness) {
+	t.Helper()
+
+	settings := []discordant.Setting{
+		{Key: "welcome.channel", Type: discordant.SettingString, Default: "general", Description: "welcome channel"},
+		{
+			Key:     "limits.max",
+			Type:    discordant.SettingInt,
+			Default: 10,
+			Validate: func(value any) error {
+				if value.(int) <= 0 {
+					return errors.New("must be positive")
+				}
+
+				return nil
+			},
+		},
+		{Key: "feature.on", Type: discordant.SettingBool},
+		{Key: "cooldown", Type: discordant.SettingDuration, Default: time.Minute},
+	}
+
+	for _, setting := range settings {
+		if err := h.Bot.AddSetting(setting); err != nil {
+			t.Fatalf("AddSetting %s: %s", setting.Key, err)
+		}
+	}
+
+	h.Bot.AddSettingsCommands()
+}
+
+func TestSettingsCommands(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	addSettings(t, h)
+
+	h.Exec(discordanttest.AdminChannelID, "!config list")
+	h.AssertMessageContains("limits.max (int) = 10 [default]")
+	h.AssertMessageContains("welcome.channel (string) = general [default]: welcome channel")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config set limits.max 25")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config get limits.max")
+	h.AssertMessage("```limits.max (int) = 25\n```")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config set limits.max 0")
+	h.AssertMessageContains("must be positive")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config set feature.on maybe")
+	h.AssertMessageContains(`"maybe" is not bool`)
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config get unknown")
+	h.AssertMessageContains(`setting "unknown" not found`)
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config reset limits.max")
+	h.AssertSuccess()
+
+	limit, err := h.Bot.Settings(discordanttest.GuildID).Int("limits.max")
+	if err != nil || limit != 10 {
+		t.Errorf("limits.max after reset %d, %v", limit, err)
+	}
+}
+
+func TestSettingsPerGuild(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	addSettings(t, h)
+
+	h.Exec(discordanttest.AdminChannelID, "!config set cooldown 90s")
+	h.AssertSuccess()
+
+	cooldown, err := h.Bot.Settings(discordanttest.GuildID).Duration("cooldown")
+	if err != nil || cooldown != 90*time.Second {
+		t.Errorf("cooldown of the guild %s, %v", cooldown, err)
+	}
+
+	const another = "700000000000000008"
+
+	cooldown, err = h.Bot.Settings(another).Duration("cooldown")
+	if err != nil || cooldown != time.Minute {
+		t.Errorf("cooldown of another guild %s, %v", cooldown, err)
+	}
+
+	// Another guild is configured from the admin channel with guild argument.
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config guild:"+another+" set cooldown 5m")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config guild:"+another+" get cooldown")
+	h.AssertMessage("```cooldown (duration) = 5m0s\n```")
+
+	cooldown, err = h.Bot.Settings(another).Duration("cooldown")
+	if err != nil || cooldown != 5*time.Minute {
+		t.Errorf("cooldown of another guild set with guild argument %s, %v", cooldown, err)
+	}
+
+	cooldown, err = h.Bot.Settings(discordanttest.GuildID).Duration("cooldown")
+	if err != nil || cooldown != 90*time.Second {
+		t.Errorf("cooldown of the admin guild is changed to %s, %v", cooldown, err)
+	}
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!config guild:general list")
+	h.AssertMessageContains(`invalid guild id "general"`)
+
+	if _, err := h.Bot.Settings("").String("cooldown"); !errors.Is(err, discordant.ErrInvalidSetting) {
+		t.Errorf("reading duration as string: %v", err)
+	}
+
+	if _, err := h.Bot.Settings("").Get("missing"); !errors.Is(err, discordant.ErrUnknownSetting) {
+		t.Errorf("reading unknown setting: %v", err)
+	}
+}
+
+func TestAddSettingErrors(t *testing.T) {
+	h := discordanttest.New(t, nil)
+
+	if err := h.Bot.AddSetting(discordant.Setting{Key: "bad key"}); !errors.Is(err, discordant.ErrInvalidSetting) {
+		t.Errorf("invalid key: %v", err)
+	}
+
+	err := h.Bot.AddSetting(discordant.Setting{Key: "n", Type: discordant.SettingInt, Default: "1"})
+	if !errors.Is(err, discordant.ErrInvalidSetting) {
+		t.Errorf("invalid default: %v", err)
+	}
+
+	if err := h.Bot.AddSetting(discordant.Setting{Key: "n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Bot.AddSetting(discordant.Setting{Key: "n"}); !errors.Is(err, discordant.ErrDuplicateSetting) {
+		t.Errorf("duplicate: %v", err)
+	}
+}
+
+func TestSettingDuration(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	addSettings(t, h)
+
+	settings := h.Bot.Settings(discordanttest.GuildID)
+
+	tests := []struct {
+		raw      string
+		expected time.Duration
+	}{
+		{raw: "0", expected: 0},
+		{raw: "0s", expected: 0},
+		{raw: "500ms", expected: 500 * time.Millisecond},
+		{raw: "1.5h", expected: 90 * time.Minute},
+		{raw: "2 days", expected: 48 * time.Hour},
+		{raw: "1h30m", expected: 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if err := settings.Set("cooldown", tt.raw); err != nil {
+			t.Errorf("Set %q: %s", tt.raw, err)
+
+			continue
+		}
+
+		// Value is read back from its stored form, not the default.
+		if value, err := settings.Duration("cooldown"); err != nil || value != tt.expected {
+			t.Errorf("%q is %s, %v, want %s", tt.raw, value, err, tt.expected)
+		}
+	}
+
+	if err := settings.Set("cooldown", "soon"); !errors.Is(err, discordant.ErrInvalidSetting) {
+		t.Errorf("invalid duration: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package discordant
+
+import "github.com/outdead/discordant/internal/session"
+
+// ShardStatus describes gateway connection of a single shard: whether it is
+// connected and ready, heartbeat latency and number of reconnects.
+type ShardStatus = session.ShardStatus
+
+// Shards returns status of gateway shards opened by the process. It returns
+// nil if the transport doesn't report shards.
+func (d *Discordant) Shards() []ShardStatus {
+	transport := d.session
+	if nc, ok := transport.(nopCloser); ok {
+		transport = nc.Transport
+	}
+
+	if ses, ok := transport.(sharder); ok {
+		return ses.Shards()
+	}
+
+	return nil
+}
+
+// sharding returns shards settings for the session.
+func (cfg *Config) sharding() session.Sharding {
+	return session.Sharding{
+		Count: cfg.ShardCount,
+		Auto:  cfg.AutoShard,
+		IDs:   append([]int(nil), cfg.Shards...),
+	}
+}
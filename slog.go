@@ -0,0 +1,184 @@
+package discordant
+
+import (
+	ctx "context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// slogLog is Logger implementation over *slog.Logger.
+type slogLog struct {
+	logger *slog.Logger
+}
+
+var (
+	_ FieldLogger = (*slogLog)(nil)
+	_ LevelLogger = (*slogLog)(nil)
+)
+
+// NewSlogLogger creates Logger that writes to *slog.Logger. Warning level is
+// mapped to slog.LevelWarn. Fields are passed as slog attributes.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLog{logger: logger}
+}
+
+func (l *slogLog) WithFields(fields Fields) Logger {
+	args := make([]any, 0, len(fields)*2)
+
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+
+	return &slogLog{logger: l.logger.With(args...)}
+}
+
+func (l *slogLog) Enabled(level Level) bool {
+	return l.logger.Enabled(ctx.Background(), toSlogLevel(level))
+}
+
+func (l *slogLog) Debugf(f string, v ...interface{})   { l.log(slog.LevelDebug, fmt.Sprintf(f, v...)) }
+func (l *slogLog) Infof(f string, v ...interface{})    { l.log(slog.LevelInfo, fmt.Sprintf(f, v...)) }
+func (l *slogLog) Warningf(f string, v ...interface{}) { l.log(slog.LevelWarn, fmt.Sprintf(f, v...)) }
+func (l *slogLog) Errorf(f string, v ...interface{})   { l.log(slog.LevelError, fmt.Sprintf(f, v...)) }
+func (l *slogLog) Debug(args ...interface{})           { l.log(slog.LevelDebug, fmt.Sprint(args...)) }
+func (l *slogLog) Info(args ...interface{})            { l.log(slog.LevelInfo, fmt.Sprint(args...)) }
+func (l *slogLog) Warning(args ...interface{})         { l.log(slog.LevelWarn, fmt.Sprint(args...)) }
+func (l *slogLog) Error(args ...interface{})           { l.log(slog.LevelError, fmt.Sprint(args...)) }
+func (l *slogLog) Debugln(args ...interface{})         { l.log(slog.LevelDebug, sprintln(args...)) }
+func (l *slogLog) Infoln(args ...interface{})          { l.log(slog.LevelInfo, sprintln(args...)) }
+func (l *slogLog) Warningln(args ...interface{})       { l.log(slog.LevelWarn, sprintln(args...)) }
+func (l *slogLog) Errorln(args ...interface{})         { l.log(slog.LevelError, sprintln(args...)) }
+
+func (l *slogLog) log(level slog.Level, msg string) {
+	l.logger.Log(ctx.Background(), level, msg)
+}
+
+// slogHandler is slog.Handler implementation over Logger.
+type slogHandler struct {
+	logger Logger
+	group  string
+	attrs  []slog.Attr
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// NewSlogHandler creates slog.Handler that writes to Logger, so that Logger can be
+// used as *slog.Logger backend with slog.New. Attributes are passed as fields.
+func NewSlogHandler(logger Logger) slog.Handler {
+	return &slogHandler{logger: logger}
+}
+
+// Enabled reports whether Logger writes lines of the level. It is true for
+// all levels if Logger doesn't implement LevelLogger.
+func (h *slogHandler) Enabled(_ ctx.Context, level slog.Level) bool {
+	return enabled(h.logger, fromSlogLevel(level))
+}
+
+// Handle writes record to Logger with the record level.
+func (h *slogHandler) Handle(_ ctx.Context, record slog.Record) error {
+	fields := make(Fields, len(h.attrs)+record.NumAttrs())
+
+	for _, attr := range h.attrs {
+		addAttr(fields, "", attr)
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(fields, h.group, attr)
+
+		return true
+	})
+
+	logger := WithFields(h.logger, fields)
+
+	switch fromSlogLevel(record.Level) {
+	case LevelError:
+		logger.Error(record.Message)
+	case LevelWarning:
+		logger.Warning(record.Message)
+	case LevelInfo:
+		logger.Info(record.Message)
+	default:
+		logger.Debug(record.Message)
+	}
+
+	return nil
+}
+
+// WithAttrs returns handler with additional attributes.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := slogHandler{logger: h.logger, group: h.group, attrs: append([]slog.Attr(nil), h.attrs...)}
+
+	for _, attr := range attrs {
+		if h.group != "" {
+			attr.Key = h.group + "." + attr.Key
+		}
+
+		next.attrs = append(next.attrs, attr)
+	}
+
+	return &next
+}
+
+// WithGroup returns handler that qualifies attribute keys with the group name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+
+	return &slogHandler{logger: h.logger, group: group, attrs: h.attrs}
+}
+
+func addAttr(fields Fields, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+
+	key := attr.Key
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, sub := range attr.Value.Group() {
+			addAttr(fields, key, sub)
+		}
+
+		return
+	}
+
+	fields[key] = attr.Value.Any()
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarning:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarning
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
@@ -0,0 +1,291 @@
+package discordant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// storePurgeInterval is the number of Set calls after which memory store
+// drops expired keys.
+const storePurgeInterval = 100
+
+// Store is a key-value storage of bot state such as per-guild settings,
+// reminders and toggles. Keys are slash separated paths like
+// `settings/<guild>/<key>`, so that related keys can be listed by prefix.
+type Store interface {
+	// Get returns value by key. It returns ErrKeyNotFound if the key is
+	// missing or expired.
+	Get(key string) ([]byte, error)
+
+	// Set sets value by key. Zero ttl means that the value never expires.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete deletes value by key. Deleting missing key is not an error.
+	Delete(key string) error
+
+	// List returns sorted keys with the prefix that are not expired.
+	List(prefix string) ([]string, error)
+}
+
+// Store returns Discordant store. It is in-memory store unless SetStore
+// option is used.
+func (d *Discordant) Store() Store {
+	return d.store
+}
+
+// NewPrefixStore returns Store that adds the prefix to keys of the store.
+// It can be used to share one store between several features or bots.
+func NewPrefixStore(store Store, prefix string) Store {
+	return &prefixStore{store: store, prefix: prefix}
+}
+
+type prefixStore struct {
+	store  Store
+	prefix string
+}
+
+func (s *prefixStore) Get(key string) ([]byte, error) {
+	return s.store.Get(s.prefix + key)
+}
+
+func (s *prefixStore) Set(key string, value []byte, ttl time.Duration) error {
+	return s.store.Set(s.prefix+key, value, ttl)
+}
+
+func (s *prefixStore) Delete(key string) error {
+	return s.store.Delete(s.prefix + key)
+}
+
+func (s *prefixStore) List(prefix string) ([]string, error) {
+	keys, err := s.store.List(s.prefix + prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, s.prefix)
+	}
+
+	return keys, nil
+}
+
+// storeEntry is a value with optional expiration time.
+type storeEntry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+func (e storeEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// memoryStore keeps values in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]storeEntry
+	sets    int
+}
+
+// NewMemoryStore creates Store that keeps values in memory. Values are lost
+// on restart.
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]storeEntry)}
+}
+
+func (s *memoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, fmt.Errorf("discordant store: %w: %s", ErrKeyNotFound, key)
+	}
+
+	return append([]byte(nil), entry.Value...), nil
+}
+
+func (s *memoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, ttl)
+
+	if s.sets++; s.sets%storePurgeInterval == 0 {
+		s.purge()
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+func (s *memoryStore) List(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	keys := make([]string, 0)
+
+	for key, entry := range s.entries {
+		if strings.HasPrefix(key, prefix) && !entry.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// set must be called under the lock.
+func (s *memoryStore) set(key string, value []byte, ttl time.Duration) {
+	entry := storeEntry{Value: append([]byte(nil), value...)}
+
+	if ttl > 0 {
+		entry.Expires = time.Now().Add(ttl)
+	}
+
+	s.entries[key] = entry
+}
+
+// purge drops expired keys. It must be called under the lock.
+func (s *memoryStore) purge() {
+	now := time.Now()
+
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// fileStore keeps values in JSON file and in memory.
+type fileStore struct {
+	memoryStore
+	path string
+}
+
+// NewFileStore creates Store that keeps values in JSON file. The file is
+// created on the first change and rewritten atomically on every change, so
+// the store suits bot state rather than large data.
+func NewFileStore(path string) (Store, error) {
+	s := fileStore{
+		memoryStore: memoryStore{entries: make(map[string]storeEntry)},
+		path:        path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("discordant store: read: %w", err)
+	}
+
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, fmt.Errorf("discordant store: parse %s: %w", path, err)
+		}
+	}
+
+	return &s, nil
+}
+
+func (s *fileStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.entries[key]
+
+	s.set(key, value, ttl)
+
+	if err := s.write(); err != nil {
+		s.restore(key, previous, ok)
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *fileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+
+	delete(s.entries, key)
+
+	if err := s.write(); err != nil {
+		s.restore(key, previous, ok)
+
+		return err
+	}
+
+	return nil
+}
+
+// restore brings back the entry that is not written to the file, so memory
+// matches the file. It must be called under the lock.
+func (s *fileStore) restore(key string, entry storeEntry, ok bool) {
+	if ok {
+		s.entries[key] = entry
+	} else {
+		delete(s.entries, key)
+	}
+}
+
+// write drops expired keys and writes the file. It must be called under
+// the lock.
+func (s *fileStore) write() error {
+	s.purge()
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("discordant store: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("discordant store: write: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to temporary file and renames it to path, so
+// readers never see partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
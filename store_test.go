@@ -0,0 +1,201 @@
+package discordant_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+)
+
+func TestStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) discordant.Store{
+		"memory": func(*testing.T) discordant.Store { return discordant.NewMemoryStore() },
+		"file": func(t *testing.T) discordant.Store {
+			store, err := discordant.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return store
+		},
+		"prefix": func(*testing.T) discordant.Store {
+			return discordant.NewPrefixStore(discordant.NewMemoryStore(), "bot/")
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+
+			if _, err := store.Get("missing"); !errors.Is(err, discordant.ErrKeyNotFound) {
+				t.Errorf("Get missing key: %v, want ErrKeyNotFound", err)
+			}
+
+			for _, key := range []string{"b/2", "a/1", "b/1", "c"} {
+				if err := store.Set(key, []byte(key), 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := store.Set("b/ttl", []byte("short"), 20*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+
+			keys, err := store.List("b/")
+			if err != nil || !reflect.DeepEqual(keys, []string{"b/1", "b/2", "b/ttl"}) {
+				t.Errorf("List: %v, %v", keys, err)
+			}
+
+			value, err := store.Get("a/1")
+			if err != nil || string(value) != "a/1" {
+				t.Errorf("Get: %q, %v", value, err)
+			}
+
+			// Returned value is a copy.
+			value[0] = 'x'
+
+			if value, _ := store.Get("a/1"); string(value) != "a/1" {
+				t.Errorf("value is changed through Get result: %q", value)
+			}
+
+			time.Sleep(30 * time.Millisecond)
+
+			if _, err := store.Get("b/ttl"); !errors.Is(err, discordant.ErrKeyNotFound) {
+				t.Errorf("Get expired key: %v, want ErrKeyNotFound", err)
+			}
+
+			if keys, _ := store.List("b/"); !reflect.DeepEqual(keys, []string{"b/1", "b/2"}) {
+				t.Errorf("List with expired key: %v", keys)
+			}
+
+			for _, key := range []string{"b/1", "missing"} {
+				if err := store.Delete(key); err != nil {
+					t.Errorf("Delete %s: %s", key, err)
+				}
+			}
+
+			if keys, _ := store.List(""); !reflect.DeepEqual(keys, []string{"a/1", "b/2", "c"}) {
+				t.Errorf("List after delete: %v", keys)
+			}
+		})
+	}
+}
+
+func TestPrefixStore(t *testing.T) {
+	shared := discordant.NewMemoryStore()
+	store := discordant.NewPrefixStore(shared, "alpha/")
+
+	if err := store.Set("key", []byte("value"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := shared.Get("alpha/key"); err != nil || string(value) != "value" {
+		t.Errorf("shared store value %q, %v", value, err)
+	}
+
+	if err := shared.Set("beta/key", []byte("other"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, err := store.List(""); err != nil || !reflect.DeepEqual(keys, []string{"key"}) {
+		t.Errorf("List: %v, %v", keys, err)
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	store, err := discordant.NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file is created before the first change: %v", err)
+	}
+
+	for i, key := range []string{"kept", "deleted", "expired"} {
+		if err := store.Set(key, []byte(key), time.Duration(i/2)*20*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Delete("deleted"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	reloaded, err := discordant.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %s", err)
+	}
+
+	if keys, err := reloaded.List(""); err != nil || !reflect.DeepEqual(keys, []string{"kept"}) {
+		t.Errorf("reloaded keys %v, %v", keys, err)
+	}
+
+	// Temporary files are renamed to the store file.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("directory has %d files, want only the store file", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := discordant.NewFileStore(path); err == nil {
+		t.Error("corrupted file is loaded")
+	}
+}
+
+func TestFileStoreWriteFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := discordant.NewFileStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Set("key", []byte("old"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes fail without the directory.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Set("key", []byte("new"), 0); err == nil {
+		t.Fatal("expected write error")
+	}
+
+	if err := store.Set("other", []byte("new"), 0); err == nil {
+		t.Fatal("expected write error")
+	}
+
+	if err := store.Delete("key"); err == nil {
+		t.Fatal("expected write error")
+	}
+
+	if value, err := store.Get("key"); err != nil || string(value) != "old" {
+		t.Errorf("value after failed writes %q, %v", value, err)
+	}
+
+	if _, err := store.Get("other"); !errors.Is(err, discordant.ErrKeyNotFound) {
+		t.Errorf("failed Set is kept in memory: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Text command actions.
+const (
+	TextCommandSet    = "set"
+	TextCommandAppend = "append"
+	TextCommandReset  = "reset"
+)
+
+// AddTextCommand adds text command such as `rules`, `faq` or `motd`. The
+// command `<name>` sends the text to everyone. Admin channel commands
+// `<name> set <text>` and `<name> append <text>` replace and extend the text,
+// `<name> reset` restores defaultText. The text can be passed inline or as
+// the first attachment. It is kept in Discordant Store, so it survives
+// restarts if the store is persistent. Every guild has its own text. Admin
+// commands change the text of the admin channel guild unless another guild is
+// selected with GuildArgument: `<name> set guild:<id> <text>`. Options are
+// applied to all commands.
+func (d *Discordant) AddTextCommand(name, defaultText string, options ...CommandOption) {
+	t := textCommand{discordant: d, name: name, defaultText: defaultText}
+
+	d.ALL(name, t.show, append([]CommandOption{
+		MiddlewareDescription(fmt.Sprintf("shows %s", name)),
+	}, options...)...)
+
+	d.ADMIN(name+DefaultCommandDelimiter+TextCommandSet, t.set, append([]CommandOption{
+		MiddlewareDescription(fmt.Sprintf("replaces %s with text or attachment", name)),
+	}, options...)...)
+
+	d.ADMIN(name+DefaultCommandDelimiter+TextCommandAppend, t.append, append([]CommandOption{
+		MiddlewareDescription(fmt.Sprintf("appends text or attachment to %s", name)),
+	}, options...)...)
+
+	d.ADMIN(name+DefaultCommandDelimiter+TextCommandReset, t.reset, append([]CommandOption{
+		MiddlewareDescription(fmt.Sprintf("restores default %s", name)),
+	}, options...)...)
+}
+
+type textCommand struct {
+	discordant  *Discordant
+	name        string
+	defaultText string
+}
+
+// key returns store key of the text in the guild. Texts sent outside of
+// guilds share the settings global scope.
+func (t *textCommand) key(guildID string) string {
+	if guildID == "" {
+		guildID = settingsGlobal
+	}
+
+	return "text/" + guildID + "/" + t.name
+}
+
+func (t *textCommand) text(guildID string) (string, error) {
+	data, err := t.discordant.store.Get(t.key(guildID))
+	if errors.Is(err, ErrKeyNotFound) {
+		return t.defaultText, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("discordant text %s: %w", t.name, err)
+	}
+
+	return string(data), nil
+}
+
+func (t *textCommand) show(ctx Context) error {
+	text, err := t.text(ctx.Request().GuildID)
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		return ctx.Send(fmt.Sprintf("%s is not set", t.name))
+	}
+
+	return ctx.Send(text)
+}
+
+func (t *textCommand) set(ctx Context) error {
+	guildID, text, err := t.input(ctx)
+	if err != nil {
+		return err
+	}
+
+	return t.save(ctx, guildID, text)
+}
+
+func (t *textCommand) append(ctx Context) error {
+	guildID, text, err := t.input(ctx)
+	if err != nil {
+		return err
+	}
+
+	current, err := t.text(guildID)
+	if err != nil {
+		return err
+	}
+
+	if current != "" {
+		text = current + "\n" + text
+	}
+
+	return t.save(ctx, guildID, text)
+}
+
+func (t *textCommand) reset(ctx Context) error {
+	guildID, _, err := cutGuild(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := t.discordant.store.Delete(t.key(guildID)); err != nil {
+		return fmt.Errorf("discordant text %s: %w", t.name, err)
+	}
+
+	return ctx.Success()
+}
+
+func (t *textCommand) save(ctx Context, guildID, text string) error {
+	if err := t.discordant.store.Set(t.key(guildID), []byte(text), 0); err != nil {
+		return fmt.Errorf("discordant text %s: %w", t.name, err)
+	}
+
+	return ctx.Success()
+}
+
+// input returns selected guild and inline text or the first attachment body.
+func (t *textCommand) input(ctx Context) (string, string, error) {
+	guildID, text, err := cutGuild(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	if text != "" {
+		return guildID, text, nil
+	}
+
+	text, err = ctx.QueryAttachmentBodyFirst()
+	if errors.Is(err, ErrNoAttachment) {
+		return "", "", WrapUserError("text or attachment is required", err)
+	}
+
+	if err != nil {
+		return "", "", fmt.Errorf("discordant text %s: %w", t.name, err)
+	}
+
+	if text = strings.TrimSpace(text); text == "" {
+		return "", "", NewUserError("text or attachment is required")
+	}
+
+	return guildID, text, nil
+}
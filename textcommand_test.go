@@ -0,0 +1,123 @@
+package discordant_test
+
+import (
+	"testing"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestTextCommand(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddTextCommand("rules", "be nice")
+
+	h.Exec(discordanttest.GeneralChannelID, "!rules")
+	h.AssertMessage("be nice")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!rules set no spam")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!rules append no ads")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!rules")
+	h.AssertMessage("no spam\nno ads")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!rules reset")
+	h.Exec(discordanttest.GeneralChannelID, "!rules")
+	h.AssertMessage("be nice")
+}
+
+func TestTextCommandAttachment(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddTextCommand("faq", "")
+
+	h.Exec(discordanttest.GeneralChannelID, "!faq")
+	h.AssertMessage("faq is not set")
+
+	h.Reset()
+	h.Dispatch(discordanttest.Request{
+		ChannelID:   discordanttest.AdminChannelID,
+		Content:     "!faq set",
+		Attachments: []discordanttest.Attachment{{Filename: "faq.txt", Content: "Q: why?\nA: because\n"}},
+	})
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!faq")
+	h.AssertMessage("Q: why?\nA: because")
+}
+
+func TestTextCommandAccess(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddTextCommand("rules", "be nice")
+
+	h.Exec(discordanttest.GeneralChannelID, "!rules set anything goes")
+	h.AssertNoMessages()
+
+	h.Exec(discordanttest.AdminChannelID, "!rules set")
+	h.AssertMessageContains("text or attachment is required")
+
+	value, err := h.Bot.Store().List("text/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(value) != 0 {
+		t.Errorf("text is stored: %v", value)
+	}
+
+	if _, ok := h.Bot.Commands()["rules"+discordant.DefaultCommandDelimiter+discordant.TextCommandSet]; !ok {
+		t.Errorf("set command is not registered")
+	}
+}
+
+func TestTextCommandPerGuild(t *testing.T) {
+	const otherGuildID = "700000000000000010"
+
+	h := discordanttest.New(t, nil)
+	h.Bot.AddTextCommand("rules", "be nice")
+
+	h.Exec(discordanttest.AdminChannelID, "!rules set no spam")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Dispatch(discordanttest.Request{
+		ChannelID: discordanttest.GeneralChannelID,
+		GuildID:   otherGuildID,
+		Content:   "!rules",
+	})
+	h.AssertMessage("be nice")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!rules")
+	h.AssertMessage("no spam")
+
+	// Text of another guild is changed from the admin channel with guild argument.
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!rules set guild:"+otherGuildID+" no ads")
+	h.Exec(discordanttest.AdminChannelID, "!rules append guild:"+otherGuildID+" no bots")
+	h.Dispatch(discordanttest.Request{
+		ChannelID: discordanttest.GeneralChannelID,
+		GuildID:   otherGuildID,
+		Content:   "!rules",
+	})
+	h.AssertMessage("no ads\nno bots")
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!rules")
+	h.AssertMessage("no spam")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!rules reset guild:"+otherGuildID)
+	h.Dispatch(discordanttest.Request{
+		ChannelID: discordanttest.GeneralChannelID,
+		GuildID:   otherGuildID,
+		Content:   "!rules",
+	})
+	h.AssertMessage("be nice")
+}
@@ -0,0 +1,90 @@
+package discordant
+
+import (
+	"errors"
+	"fmt"
+)
+
+// togglesPrefix is the store prefix of disabled commands.
+const togglesPrefix = "toggles/"
+
+// Toggle command names.
+const (
+	CommandEnable  = "enable"
+	CommandDisable = "disable"
+)
+
+// AddToggleCommands adds `enable <command>` and `disable <command>` route handlers
+// to admin channel. They allow to put a command into maintenance at runtime
+// without restarting the bot.
+func (d *Discordant) AddToggleCommands(options ...CommandOption) {
+	d.ADMIN(CommandEnable, d.toggleHandler(false), append([]CommandOption{
+		MiddlewareDescription("enables command"),
+	}, options...)...)
+
+	d.ADMIN(CommandDisable, d.toggleHandler(true), append([]CommandOption{
+		MiddlewareDescription("disables command"),
+	}, options...)...)
+}
+
+func (d *Discordant) toggleHandler(disable bool) HandlerFunc {
+	return func(ctx Context) error {
+		name := ctx.QueryString()
+		if name == "" {
+			return NewUserError("command name is required")
+		}
+
+		if name == CommandEnable || name == CommandDisable {
+			return NewUserError(fmt.Sprintf("command %q can't be toggled", name))
+		}
+
+		toggle := d.Enable
+		if disable {
+			toggle = d.Disable
+		}
+
+		err := toggle(name)
+		if errors.Is(err, ErrCommandNotFound) {
+			return WrapUserError(fmt.Sprintf("command %q not found", name), err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return ctx.Success()
+	}
+}
+
+// setDisabled changes the command state and keeps it in the store. The
+// previous state is restored if the store fails.
+func (d *Discordant) setDisabled(name string, disabled bool) error {
+	previous, err := d.commands.setDisabled(name, disabled)
+	if err != nil {
+		return err
+	}
+
+	if disabled {
+		err = d.store.Set(togglesPrefix+name, []byte("disabled"), 0)
+	} else {
+		err = d.store.Delete(togglesPrefix + name)
+	}
+
+	if err != nil {
+		_, _ = d.commands.setDisabled(name, previous)
+
+		return fmt.Errorf("discordant toggle: %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// storedDisabled reports whether the command is disabled in the store.
+func (d *Discordant) storedDisabled(name string) bool {
+	_, err := d.store.Get(togglesPrefix + name)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		d.logger.Errorf("discordant toggle: %s: %s", name, err)
+	}
+
+	return err == nil
+}
@@ -0,0 +1,142 @@
+package discordant_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/outdead/discordant"
+	"github.com/outdead/discordant/discordanttest"
+)
+
+func TestToggleCommands(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddToggleCommands()
+	h.Bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Send("pong") })
+
+	h.Exec(discordanttest.AdminChannelID, "!disable ping")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessageContains(discordant.DefaultCommandDisabledMessage)
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!enable ping")
+	h.AssertSuccess()
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessage("pong")
+}
+
+func TestToggleCommandsErrors(t *testing.T) {
+	h := discordanttest.New(t, nil)
+	h.Bot.AddToggleCommands()
+
+	h.Exec(discordanttest.AdminChannelID, "!disable")
+	h.AssertMessageContains("command name is required")
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!disable disable")
+	h.AssertMessageContains(`command "disable" can't be toggled`)
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!enable missing")
+	h.AssertMessageContains(`command "missing" not found`)
+
+	h.Reset()
+	h.Exec(discordanttest.GeneralChannelID, "!disable enable")
+	h.AssertNoMessages()
+}
+
+func TestTogglePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	pong := func(ctx discordant.Context) error { return ctx.Send("pong") }
+
+	run := func() *discordanttest.Harness {
+		store, err := discordant.NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h := discordanttest.New(t, nil, discordant.SetStore(store))
+		h.Bot.AddToggleCommands()
+		h.Bot.ALL("ping", pong)
+
+		return h
+	}
+
+	h := run()
+	h.Exec(discordanttest.AdminChannelID, "!disable ping")
+	h.AssertSuccess()
+	h.Close()
+
+	// Bot restarted with the same store keeps the command disabled.
+	h = run()
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessageContains(discordant.DefaultCommandDisabledMessage)
+
+	h.Reset()
+	h.Exec(discordanttest.AdminChannelID, "!enable ping")
+	h.AssertSuccess()
+	h.Close()
+
+	h = run()
+	h.Exec(discordanttest.GeneralChannelID, "!ping")
+	h.AssertMessage("pong")
+}
+
+// failingStore fails writes when fail is set.
+type failingStore struct {
+	discordant.Store
+
+	fail bool
+}
+
+func (s *failingStore) Set(key string, value []byte, ttl time.Duration) error {
+	if s.fail {
+		return errors.New("disk is full")
+	}
+
+	return s.Store.Set(key, value, ttl)
+}
+
+func (s *failingStore) Delete(key string) error {
+	if s.fail {
+		return errors.New("disk is full")
+	}
+
+	return s.Store.Delete(key)
+}
+
+func TestToggleStoreFailure(t *testing.T) {
+	store := &failingStore{Store: discordant.NewMemoryStore()}
+
+	h := discordanttest.New(t, nil, discordant.SetStore(store))
+	h.Bot.ALL("ping", func(ctx discordant.Context) error { return ctx.Send("pong") })
+
+	if err := h.Bot.Disable("ping"); err != nil {
+		t.Fatal(err)
+	}
+
+	store.fail = true
+
+	// Failed repeated disable keeps the command disabled.
+	if err := h.Bot.Disable("ping"); err == nil {
+		t.Fatal("expected store error")
+	}
+
+	if !h.Bot.Commands()["ping"].Disabled {
+		t.Fatal("command is enabled by failed disable")
+	}
+
+	if err := h.Bot.Enable("ping"); err == nil {
+		t.Fatal("expected store error")
+	}
+
+	if !h.Bot.Commands()["ping"].Disabled {
+		t.Error("command is enabled in spite of store error")
+	}
+}
@@ -0,0 +1,94 @@
+package discordant
+
+import (
+	ctx "context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Span names.
+const (
+	SpanCommand  = "discordant.command"
+	SpanRoute    = "discordant.route"
+	SpanAccess   = "discordant.access"
+	SpanQueue    = "discordant.queue"
+	SpanHandler  = "discordant.handler"
+	SpanJob      = "discordant.job"
+	SpanSend     = "discord.message.send"
+	SpanReaction = "discord.reaction.add"
+	SpanEdit     = "discord.message.edit"
+	SpanRespond  = "discord.interaction.respond"
+	SpanDM       = "discord.channel.dm"
+)
+
+// Span attribute keys.
+const (
+	AttrCommand     = "discordant.command"
+	AttrOutcome     = "discordant.outcome"
+	AttrChannel     = "discord.channel_id"
+	AttrGuild       = "discord.guild_id"
+	AttrUser        = "discord.user_id"
+	AttrRequestID   = "discordant.request_id"
+	AttrMessageKind = "discord.message.kind"
+	AttrJob         = "discordant.job"
+)
+
+// Attribute is a span attribute.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates span attribute.
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans. Started span is a child of the span stored in parent
+// context and the returned context carries the started span.
+type Tracer interface {
+	Start(parent ctx.Context, name string, attrs ...Attribute) (ctx.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(parent ctx.Context, _ string, _ ...Attribute) (ctx.Context, Span) {
+	return parent, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+func (nopSpan) RecordError(error)          {}
+func (nopSpan) End()                       {}
+
+// endSpan records error if it is not nil and ends span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// startCommandSpan starts root span of the command handling.
+func (d *Discordant) startCommandSpan(message *discordgo.MessageCreate) (ctx.Context, Span) {
+	attrs := []Attribute{Attr(AttrChannel, message.ChannelID)}
+
+	if message.GuildID != "" {
+		attrs = append(attrs, Attr(AttrGuild, message.GuildID))
+	}
+
+	if message.Author != nil {
+		attrs = append(attrs, Attr(AttrUser, message.Author.ID))
+	}
+
+	return d.tracer.Start(ctx.Background(), SpanCommand, attrs...)
+}
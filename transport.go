@@ -0,0 +1,67 @@
+package discordant
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/outdead/discordant/internal/session"
+)
+
+// Transport is the subset of Discord API used by Discordant. It is implemented
+// by *discordgo.Session, so a real connection can be used as is, and allows to
+// inject a fake or alternative transport with SetTransport option.
+//
+// AddHandler receives discordgo event handlers such as
+// func(*discordgo.Session, *discordgo.MessageCreate). Alternative transports may
+// call them with nil session.
+type Transport interface {
+	User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(
+		channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption,
+	) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(
+		channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption,
+	) (*discordgo.Message, error)
+	MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error
+	ChannelMessageEditComplex(data *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	InteractionRespond(
+		interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption,
+	) error
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	AddHandler(handler interface{}) func()
+	Close() error
+}
+
+var (
+	_ Transport = (*discordgo.Session)(nil)
+	_ Transport = (*session.Session)(nil)
+)
+
+// nopCloser prevents closing of transport that is not owned by Discordant.
+type nopCloser struct {
+	Transport
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// opener is implemented by sessions that are opened by Discordant on Run.
+type opener interface {
+	Open(intents discordgo.Intent, sharding session.Sharding) error
+}
+
+// intentsGetter is implemented by sessions that report gateway intents.
+type intentsGetter interface {
+	Intents() discordgo.Intent
+}
+
+// sharder is implemented by sessions that report gateway shards status.
+type sharder interface {
+	Shards() []session.ShardStatus
+}
+
+// ignorer is implemented by transports that report messages ignored by
+// command handler, e.g. Console prints the reason to operator.
+type ignorer interface {
+	Ignored(message *discordgo.MessageCreate, reason string)
+}